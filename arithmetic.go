@@ -0,0 +1,130 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+// Increment increments the given byte slice in place by one and returns whether it overflowed (wrapped to zero).
+// The byte slice is treated as one unsigned integer by the given byte order (endianness).
+func Increment(b []byte, bo ByteOrder) bool {
+	for i := 0; i < len(b); i++ {
+		j := significance(len(b), i, bo)
+		b[j]++
+		if b[j] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Decrement decrements the given byte slice in place by one and returns whether it underflowed (wrapped to the
+// maximum value). The byte slice is treated as one unsigned integer by the given byte order (endianness).
+func Decrement(b []byte, bo ByteOrder) bool {
+	for i := 0; i < len(b); i++ {
+		j := significance(len(b), i, bo)
+		b[j]--
+		if b[j] != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns the sum of the given byte slices and whether the addition overflowed (carry out).
+// The byte slices are treated as unsigned integers by the given byte order (endianness).
+// It returns nil if the lengths differ.
+func Add(a, b []byte, bo ByteOrder) ([]byte, bool) {
+	if len(a) != len(b) {
+		return nil, false
+	}
+	sum := make([]byte, len(a))
+	var carry uint16
+	for i := 0; i < len(a); i++ {
+		j := significance(len(a), i, bo)
+		v := uint16(a[j]) + uint16(b[j]) + carry
+		sum[j], carry = byte(v), v>>8
+	}
+	return sum, carry != 0
+}
+
+// Sub returns the difference of the given byte slices (a - b) and whether the subtraction underflowed (borrow out).
+// The byte slices are treated as unsigned integers by the given byte order (endianness).
+// It returns nil if the lengths differ.
+func Sub(a, b []byte, bo ByteOrder) ([]byte, bool) {
+	if len(a) != len(b) {
+		return nil, false
+	}
+	diff := make([]byte, len(a))
+	var borrow uint16
+	for i := 0; i < len(a); i++ {
+		j := significance(len(a), i, bo)
+		v := uint16(a[j]) - uint16(b[j]) - borrow
+		diff[j], borrow = byte(v), (v>>8)&1
+	}
+	return diff, borrow != 0
+}
+
+// Compare returns -1, 0 or +1 depending on whether a is less than, equal to or greater than b.
+// The byte slices are treated as unsigned integers by the given byte order (endianness).
+// The shorter byte slice is zero extended.
+func Compare(a, b []byte, bo ByteOrder) int {
+	return compare(a, b, false, bo)
+}
+
+// CompareSigned returns -1, 0 or +1 depending on whether a is less than, equal to or greater than b.
+// The byte slices are treated as two's complement signed integers by the given byte order (endianness).
+// The shorter byte slice is sign extended.
+func CompareSigned(a, b []byte, bo ByteOrder) int {
+	return compare(a, b, true, bo)
+}
+
+// compare compares the given byte slices.
+func compare(a, b []byte, signed bool, bo ByteOrder) int {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	ea, eb := extension(a, signed, bo), extension(b, signed, bo)
+	if signed && ea != eb {
+		// Different signs
+		if ea == 0xff {
+			return -1
+		}
+		return 1
+	}
+	// get returns the byte of the given significance (0 is the least significant byte).
+	get := func(x []byte, ext byte, i int) byte {
+		if i >= len(x) {
+			return ext
+		}
+		return x[significance(len(x), i, bo)]
+	}
+	for i := n - 1; i >= 0; i-- {
+		if x, y := get(a, ea, i), get(b, eb, i); x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// extension returns the extension byte of the given byte slice.
+func extension(b []byte, signed bool, bo ByteOrder) byte {
+	if !signed || len(b) == 0 {
+		return 0
+	}
+	if b[significance(len(b), len(b)-1, bo)]&0x80 != 0 {
+		return 0xff
+	}
+	return 0
+}
+
+// significance returns the index of the byte of the given significance (0 is the least significant byte)
+// by the given length and byte order (endianness).
+func significance(n, i int, bo ByteOrder) int {
+	if bo.Type() == ByteOrderTypeBigEndian {
+		return n - 1 - i
+	}
+	return i
+}
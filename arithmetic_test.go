@@ -0,0 +1,132 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestIncrement(t *testing.T) {
+	table := []struct {
+		arg0     []byte
+		arg1     byteman.ByteOrder
+		out      []byte
+		overflow bool
+	}{
+		{[]byte{0x00, 0x00}, &byteman.BigEndian{}, []byte{0x00, 0x01}, false},
+		{[]byte{0x00, 0xff}, &byteman.BigEndian{}, []byte{0x01, 0x00}, false},
+		{[]byte{0xff, 0xff}, &byteman.BigEndian{}, []byte{0x00, 0x00}, true},
+		{[]byte{0xff, 0x00}, &byteman.LittleEndian{}, []byte{0x00, 0x01}, false},
+		{[]byte{0xff, 0xff}, &byteman.LittleEndian{}, []byte{0x00, 0x00}, true},
+		{[]byte{}, &byteman.BigEndian{}, []byte{}, true},
+	}
+	for _, v := range table {
+		b := byteman.Resize(v.arg0, 0)
+		if overflow := byteman.Increment(b, v.arg1); !bytes.Equal(b, v.out) || overflow != v.overflow {
+			t.Errorf("got %v (%v), want %v (%v)", b, overflow, v.out, v.overflow)
+		}
+		if underflow := byteman.Decrement(b, v.arg1); !bytes.Equal(b, v.arg0) || underflow != v.overflow {
+			t.Errorf("got %v (%v), want %v (%v)", b, underflow, v.arg0, v.overflow)
+		}
+	}
+}
+
+func BenchmarkIncrement(b *testing.B) {
+	data := make([]byte, 16)
+	for i := 0; i < b.N; i++ {
+		byteman.Increment(data, &byteman.BigEndian{})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	table := []struct {
+		arg0     []byte
+		arg1     []byte
+		arg2     byteman.ByteOrder
+		out      []byte
+		overflow bool
+	}{
+		{[]byte{0x00, 0xff}, []byte{0x00, 0x01}, &byteman.BigEndian{}, []byte{0x01, 0x00}, false},
+		{[]byte{0xff, 0x00}, []byte{0x01, 0x00}, &byteman.LittleEndian{}, []byte{0x00, 0x01}, false},
+		{[]byte{0xff, 0xff}, []byte{0x00, 0x02}, &byteman.BigEndian{}, []byte{0x00, 0x01}, true},
+		{[]byte{0x12, 0x34, 0x56}, []byte{0x65, 0x43, 0x21}, &byteman.BigEndian{}, []byte{0x77, 0x77, 0x77}, false},
+	}
+	for _, v := range table {
+		sum, overflow := byteman.Add(v.arg0, v.arg1, v.arg2)
+		if !bytes.Equal(sum, v.out) || overflow != v.overflow {
+			t.Errorf("got %v (%v), want %v (%v)", sum, overflow, v.out, v.overflow)
+		}
+		diff, underflow := byteman.Sub(sum, v.arg1, v.arg2)
+		if !bytes.Equal(diff, v.arg0) || underflow != v.overflow {
+			t.Errorf("got %v (%v), want %v (%v)", diff, underflow, v.arg0, v.overflow)
+		}
+	}
+
+	// Compare with the native 64 bit operations.
+	for _, bo := range []byteman.ByteOrder{&byteman.BigEndian{}, &byteman.LittleEndian{}} {
+		x, y := uint64(0xfedcba9876543210), uint64(0x0123456789abcdef)
+		sum, _ := byteman.Add(byteman.FromUint(x, bo), byteman.FromUint(y, bo), bo)
+		if r := byteman.Uint64(sum, bo); r != x+y {
+			t.Errorf("got %x, want %x", r, x+y)
+		}
+		diff, underflow := byteman.Sub(byteman.FromUint(y, bo), byteman.FromUint(x, bo), bo)
+		if r := byteman.Uint64(diff, bo); r != y-x || !underflow {
+			t.Errorf("got %x (%v), want %x (true)", r, underflow, y-x)
+		}
+	}
+
+	if sum, _ := byteman.Add([]byte{0x01}, []byte{0x01, 0x02}, &byteman.BigEndian{}); sum != nil {
+		t.Errorf("got %v, want nil", sum)
+	}
+	if diff, _ := byteman.Sub([]byte{0x01}, []byte{0x01, 0x02}, &byteman.BigEndian{}); diff != nil {
+		t.Errorf("got %v, want nil", diff)
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	x, y := make([]byte, 16), make([]byte, 16)
+	for i := 0; i < b.N; i++ {
+		byteman.Add(x, y, &byteman.BigEndian{})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	table := []struct {
+		arg0   []byte
+		arg1   []byte
+		arg2   byteman.ByteOrder
+		out    int
+		signed int
+	}{
+		{[]byte{0x01, 0x02}, []byte{0x01, 0x02}, &byteman.BigEndian{}, 0, 0},
+		{[]byte{0x01, 0x02}, []byte{0x01, 0x03}, &byteman.BigEndian{}, -1, -1},
+		{[]byte{0x02, 0x01}, []byte{0x01, 0x03}, &byteman.BigEndian{}, 1, 1},
+		{[]byte{0x02, 0x01}, []byte{0x01, 0x03}, &byteman.LittleEndian{}, -1, -1},
+		{[]byte{0xff, 0xff}, []byte{0x00, 0x01}, &byteman.BigEndian{}, 1, -1},
+		{[]byte{0x80, 0x00}, []byte{0xff, 0xff}, &byteman.BigEndian{}, -1, -1},
+		{[]byte{0x00, 0x01}, []byte{0x01}, &byteman.BigEndian{}, 0, 0},
+		{[]byte{0xff}, []byte{0xff, 0xff}, &byteman.BigEndian{}, -1, 0},
+		{[]byte{0xff}, []byte{0x00, 0xff}, &byteman.BigEndian{}, 0, -1},
+		{[]byte{0x01, 0x00}, []byte{0x01}, &byteman.LittleEndian{}, 0, 0},
+		{[]byte{}, []byte{0x00}, &byteman.LittleEndian{}, 0, 0},
+	}
+	for _, v := range table {
+		if r := byteman.Compare(v.arg0, v.arg1, v.arg2); r != v.out {
+			t.Errorf("got %v, want %v for %v, %v", r, v.out, v.arg0, v.arg1)
+		}
+		if r := byteman.CompareSigned(v.arg0, v.arg1, v.arg2); r != v.signed {
+			t.Errorf("got %v, want %v for signed %v, %v", r, v.signed, v.arg0, v.arg1)
+		}
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	x, y := make([]byte, 16), make([]byte, 16)
+	for i := 0; i < b.N; i++ {
+		byteman.Compare(x, y, &byteman.BigEndian{})
+	}
+}
@@ -0,0 +1,311 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ASN1Class represents the ASN.1 tag class.
+type ASN1Class uint8
+
+const (
+	// ASN1ClassUniversal represents the universal class.
+	ASN1ClassUniversal ASN1Class = 0
+	// ASN1ClassApplication represents the application class.
+	ASN1ClassApplication ASN1Class = 1
+	// ASN1ClassContextSpecific represents the context-specific class.
+	ASN1ClassContextSpecific ASN1Class = 2
+	// ASN1ClassPrivate represents the private class.
+	ASN1ClassPrivate ASN1Class = 3
+)
+
+// ASN1Token represents an ASN.1 BER/DER header.
+type ASN1Token struct {
+	// Class is the tag class.
+	Class ASN1Class
+	// Constructed reports whether the value is constructed.
+	Constructed bool
+	// Tag is the tag number.
+	Tag uint64
+	// TagSize is the number of tag bytes (more than one for the high tag number form).
+	TagSize int
+	// Indefinite reports whether the length is in the indefinite form.
+	Indefinite bool
+	// LongForm reports whether the length is in the long (definite) form.
+	LongForm bool
+	// Length is the content length. It is -1 for the indefinite form.
+	Length int
+	// NonMinimal reports whether the tag or length is not minimally encoded (invalid in DER).
+	NonMinimal bool
+	// Depth is the nesting depth of the value.
+	Depth int
+	// Offset is the byte offset of the header.
+	Offset int
+	// ContentOffset is the byte offset of the content.
+	ContentOffset int
+}
+
+// IsEOC returns whether the token is an end-of-contents marker of an indefinite length value.
+func (t ASN1Token) IsEOC() bool {
+	return t.Class == ASN1ClassUniversal && !t.Constructed && t.Tag == 0 && t.Length == 0
+}
+
+// ASN1Reader represents a low-level ASN.1 BER/DER token reader.
+// Constructed values are entered, so the tokens are returned in document (depth-first) order.
+type ASN1Reader struct {
+	b      []byte
+	pos    int
+	frames []int // end offsets of the open constructed values (-1 for the indefinite form)
+}
+
+// NewASN1Reader returns a new ASN.1 token reader by the given byte slice.
+func NewASN1Reader(b []byte) *ASN1Reader {
+	return &ASN1Reader{b: b}
+}
+
+// Next returns the next token. It returns io.EOF at the end of the input and *ASN1Error for malformed encodings.
+func (r *ASN1Reader) Next() (ASN1Token, error) {
+	for len(r.frames) > 0 && r.frames[len(r.frames)-1] == r.pos {
+		r.frames = r.frames[:len(r.frames)-1]
+	}
+	if end, ok := r.definiteEnd(); ok && r.pos >= end {
+		// An indefinite length value is still open at the end of its enclosing definite length value.
+		return ASN1Token{}, &ASN1Error{Pos: r.pos, Msg: "missing end-of-contents"}
+	}
+	if r.pos >= len(r.b) {
+		if len(r.frames) > 0 {
+			return ASN1Token{}, &ASN1Error{Pos: r.pos, Msg: "unexpected end of input in constructed value"}
+		}
+		return ASN1Token{}, io.EOF
+	}
+
+	t, err := r.header()
+	if err != nil {
+		return ASN1Token{}, err
+	}
+	if t.IsEOC() {
+		if len(r.frames) == 0 || r.frames[len(r.frames)-1] != -1 {
+			return ASN1Token{}, &ASN1Error{Pos: t.Offset, Msg: "unexpected end-of-contents"}
+		}
+		r.frames = r.frames[:len(r.frames)-1]
+		r.pos = t.ContentOffset
+		return t, nil
+	}
+	if t.Constructed {
+		end := -1
+		if !t.Indefinite {
+			end = t.ContentOffset + t.Length
+		}
+		r.frames = append(r.frames, end)
+		r.pos = t.ContentOffset
+		return t, nil
+	}
+	r.pos = t.ContentOffset + t.Length
+	return t, nil
+}
+
+// Content returns the content of the given definite length token.
+// It shares the underlying array of the read byte slice.
+func (r *ASN1Reader) Content(t ASN1Token) []byte {
+	if t.Indefinite || t.ContentOffset+t.Length > len(r.b) {
+		return nil
+	}
+	return r.b[t.ContentOffset : t.ContentOffset+t.Length]
+}
+
+// Skip skips the remaining tokens of the innermost open constructed value (i.e. the children of the last
+// constructed token) including its end-of-contents marker.
+func (r *ASN1Reader) Skip() error {
+	depth := len(r.frames)
+	if depth == 0 {
+		return nil
+	}
+	if end := r.frames[depth-1]; end >= 0 {
+		r.pos, r.frames = end, r.frames[:depth-1]
+		return nil
+	}
+	for len(r.frames) >= depth {
+		if _, err := r.Next(); err != nil {
+			if err == io.EOF {
+				err = &ASN1Error{Pos: r.pos, Msg: "unexpected end of input in constructed value"}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// Offset returns the byte offset of the next token.
+func (r *ASN1Reader) Offset() int {
+	return r.pos
+}
+
+// definiteEnd returns the end offset of the innermost open definite length value, if any.
+func (r *ASN1Reader) definiteEnd() (int, bool) {
+	for i := len(r.frames) - 1; i >= 0; i-- {
+		if r.frames[i] >= 0 {
+			return r.frames[i], true
+		}
+	}
+	return 0, false
+}
+
+// header returns the token at the current offset.
+func (r *ASN1Reader) header() (ASN1Token, error) {
+	limit := len(r.b)
+	if end, ok := r.definiteEnd(); ok {
+		limit = end
+	}
+
+	b, i := r.b[:limit], r.pos
+	if i >= len(b) {
+		return ASN1Token{}, &ASN1Error{Pos: i, Msg: "unexpected end of constructed value"}
+	}
+	t := ASN1Token{Offset: i, Depth: len(r.frames)}
+	t.Class = ASN1Class(b[i] >> 6)
+	t.Constructed = b[i]&0x20 != 0
+	t.Tag = uint64(b[i] & 0x1f)
+	i++
+	if t.Tag == 0x1f {
+		// The high tag number form.
+		t.Tag = 0
+		for j := 0; ; j++ {
+			if i >= len(b) {
+				return ASN1Token{}, &ASN1Error{Pos: i, Msg: "truncated tag"}
+			}
+			if j == 0 && b[i] == 0x80 {
+				t.NonMinimal = true
+			}
+			if t.Tag>>57 != 0 {
+				return ASN1Token{}, &ASN1Error{Pos: i, Msg: "tag number overflows 64 bits"}
+			}
+			t.Tag = t.Tag<<7 | uint64(b[i]&0x7f)
+			i++
+			if b[i-1]&0x80 == 0 {
+				break
+			}
+		}
+		if t.Tag < 0x1f {
+			t.NonMinimal = true
+		}
+	}
+
+	t.TagSize = i - t.Offset
+
+	if i >= len(b) {
+		return ASN1Token{}, &ASN1Error{Pos: i, Msg: "truncated length"}
+	}
+	switch l := b[i]; {
+	case l < 0x80:
+		t.Length = int(l)
+		i++
+	case l == 0x80:
+		if !t.Constructed {
+			return ASN1Token{}, &ASN1Error{Pos: i, Msg: "indefinite length of primitive value"}
+		}
+		t.Indefinite, t.Length = true, -1
+		i++
+	case l == 0xff:
+		return ASN1Token{}, &ASN1Error{Pos: i, Msg: "reserved length byte"}
+	default:
+		n := int(l & 0x7f)
+		if i+1+n > len(b) {
+			return ASN1Token{}, &ASN1Error{Pos: i, Msg: "truncated length"}
+		}
+		var length uint64
+		for _, v := range b[i+1 : i+1+n] {
+			if length>>55 != 0 {
+				return ASN1Token{}, &ASN1Error{Pos: i, Msg: "length overflows 63 bits"}
+			}
+			length = length<<8 | uint64(v)
+		}
+		if b[i+1] == 0 || length < 0x80 {
+			t.NonMinimal = true
+		}
+		if length > uint64(len(b)) {
+			return ASN1Token{}, &ASN1Error{Pos: t.Offset, Msg: fmt.Sprintf("length %d exceeds available %d bytes", length, len(b)-i-1-n)}
+		}
+		t.LongForm, t.Length = true, int(length)
+		i += 1 + n
+	}
+	t.ContentOffset = i
+	if !t.Indefinite && t.Length > len(b)-i {
+		return ASN1Token{}, &ASN1Error{Pos: t.Offset, Msg: fmt.Sprintf("length %d exceeds available %d bytes", t.Length, len(b)-i)}
+	}
+	return t, nil
+}
+
+// ParseASN1OID returns the dotted string of the given ASN.1 OBJECT IDENTIFIER content.
+// The error position is relative to the content.
+func ParseASN1OID(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", &ASN1Error{Pos: 0, Msg: "empty object identifier"}
+	}
+	var sb strings.Builder
+	var v uint64
+	start := 0
+	for i, c := range b {
+		if i == start && c == 0x80 {
+			return "", &ASN1Error{Pos: i, Msg: "non-minimal object identifier arc"}
+		}
+		if v>>57 != 0 {
+			return "", &ASN1Error{Pos: i, Msg: "object identifier arc overflows 64 bits"}
+		}
+		v = v<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			continue
+		}
+		if start == 0 {
+			switch {
+			case v < 40:
+				sb.WriteString("0.")
+			case v < 80:
+				sb.WriteString("1.")
+				v -= 40
+			default:
+				sb.WriteString("2.")
+				v -= 80
+			}
+		} else {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.FormatUint(v, 10))
+		v, start = 0, i+1
+	}
+	if start != len(b) {
+		return "", &ASN1Error{Pos: len(b) - 1, Msg: "truncated object identifier"}
+	}
+	return sb.String(), nil
+}
+
+// ParseASN1Integer returns the value of the given ASN.1 INTEGER content (big-endian two's complement).
+// Non-minimal encodings (invalid in DER) are accepted.
+func ParseASN1Integer(b []byte) (*big.Int, error) {
+	if len(b) == 0 {
+		return nil, &ASN1Error{Pos: 0, Msg: "empty integer"}
+	}
+	n := new(big.Int).SetBytes(b)
+	if b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return n, nil
+}
+
+// ParseASN1Int64 returns the value of the given ASN.1 INTEGER content (big-endian two's complement) as int64.
+// Non-minimal encodings (invalid in DER) are accepted.
+func ParseASN1Int64(b []byte) (int64, error) {
+	n, err := ParseASN1Integer(b)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, &ASN1Error{Pos: 0, Msg: "integer overflows 64 bits"}
+	}
+	return n.Int64(), nil
+}
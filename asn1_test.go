@@ -0,0 +1,166 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+// berSequence represents a BER SEQUENCE (indefinite length) of an INTEGER, an OID, a [0] tagged
+// constructed value (non-minimal length) and a high tag number primitive.
+var berSequence = []byte{
+	0x30, 0x80,
+	0x02, 0x02, 0x01, 0x00,
+	0x06, 0x06, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d,
+	0xa0, 0x81, 0x03, 0x01, 0x01, 0xff,
+	0x5f, 0x81, 0x00, 0x01, 0x42,
+	0x00, 0x00,
+}
+
+func TestASN1Reader(t *testing.T) {
+	table := []struct {
+		out0 byteman.ASN1Token
+	}{
+		{byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Constructed: true, Tag: 16, TagSize: 1, Indefinite: true, Length: -1, Depth: 0, Offset: 0, ContentOffset: 2}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Tag: 2, TagSize: 1, Length: 2, Depth: 1, Offset: 2, ContentOffset: 4}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Tag: 6, TagSize: 1, Length: 6, Depth: 1, Offset: 6, ContentOffset: 8}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassContextSpecific, Constructed: true, Tag: 0, TagSize: 1, LongForm: true, Length: 3, NonMinimal: true, Depth: 1, Offset: 14, ContentOffset: 17}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Tag: 1, TagSize: 1, Length: 1, Depth: 2, Offset: 17, ContentOffset: 19}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassApplication, Tag: 128, TagSize: 3, Length: 1, Depth: 1, Offset: 20, ContentOffset: 24}},
+		{byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Tag: 0, TagSize: 1, Length: 0, Depth: 1, Offset: 25, ContentOffset: 27}},
+	}
+	r := byteman.NewASN1Reader(berSequence)
+	for _, v := range table {
+		tok, err := r.Next()
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if tok != v.out0 {
+			t.Errorf("got %+v, want %+v", tok, v.out0)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+
+	// A long form length which is not minimally encoded.
+	r = byteman.NewASN1Reader([]byte{0x02, 0x82, 0x00, 0x01, 0x05})
+	want := byteman.ASN1Token{Class: byteman.ASN1ClassUniversal, Tag: 2, TagSize: 1, LongForm: true, Length: 1, NonMinimal: true, Offset: 0, ContentOffset: 4}
+	if tok, err := r.Next(); err != nil || tok != want {
+		t.Errorf("got %+v %v, want %+v", tok, err, want)
+	}
+
+	r = byteman.NewASN1Reader(berSequence)
+	r.Next()
+	tok, _ := r.Next()
+	if n, err := byteman.ParseASN1Int64(r.Content(tok)); err != nil || n != 256 {
+		t.Errorf("got %v %v, want 256 nil", n, err)
+	}
+	tok, _ = r.Next()
+	if s, err := byteman.ParseASN1OID(r.Content(tok)); err != nil || s != "1.2.840.113549" {
+		t.Errorf("got %v %v, want 1.2.840.113549 nil", s, err)
+	}
+	if err := r.Skip(); err != nil || r.Offset() != 27 {
+		t.Errorf("got %v %v, want nil 27", err, r.Offset())
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+}
+
+func TestASN1ReaderError(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out0 int
+	}{
+		{[]byte{0x30, 0x03, 0x02, 0x01}, 0},
+		{[]byte{0x30, 0x03, 0x02, 0x02, 0x01}, 2},
+		{[]byte{0x02, 0x80}, 1},
+		{[]byte{0x02, 0xff}, 1},
+		{[]byte{0x02, 0x82, 0x01}, 1},
+		{[]byte{0x1f, 0x81}, 2},
+		{[]byte{0x00, 0x00}, 0},
+		{[]byte{0x30, 0x80, 0x05, 0x00}, 4},
+		{[]byte{0x30, 0x02, 0x30, 0x80, 0x00, 0x00}, 4},
+		{[]byte{0x30, 0x02, 0x30, 0x80}, 4},
+	}
+	for _, v := range table {
+		r := byteman.NewASN1Reader(v.arg0)
+		var err error
+		for err == nil {
+			_, err = r.Next()
+		}
+		var e *byteman.ASN1Error
+		if !errors.As(err, &e) {
+			t.Errorf("got %v, want *ASN1Error for %x", err, v.arg0)
+			continue
+		}
+		if e.Pos != v.out0 {
+			t.Errorf("got %v, want %v for %x", e.Pos, v.out0, v.arg0)
+		}
+	}
+}
+
+func TestParseASN1OID(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out0 string
+		out1 bool
+	}{
+		{[]byte{0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x01, 0x01, 0x0b}, "1.2.840.113549.1.1.11", false},
+		{[]byte{0x55, 0x04, 0x03}, "2.5.4.3", false},
+		{[]byte{0x88, 0x37, 0x03}, "2.999.3", false},
+		{[]byte{0x00}, "0.0", false},
+		{[]byte{}, "", true},
+		{[]byte{0x2a, 0x86}, "", true},
+		{[]byte{0x2a, 0x80, 0x01}, "", true},
+	}
+	for _, v := range table {
+		out0, err := byteman.ParseASN1OID(v.arg0)
+		if out0 != v.out0 || (err != nil) != v.out1 {
+			t.Errorf("got %v %v, want %v %v", out0, err, v.out0, v.out1)
+		}
+	}
+}
+
+func TestParseASN1Integer(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out0 string
+	}{
+		{[]byte{0x00}, "0"},
+		{[]byte{0x7f}, "127"},
+		{[]byte{0x00, 0x80}, "128"},
+		{[]byte{0x80}, "-128"},
+		{[]byte{0xff, 0x7f}, "-129"},
+		{[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, "18446744073709551616"},
+	}
+	for _, v := range table {
+		out0, err := byteman.ParseASN1Integer(v.arg0)
+		if err != nil || out0.String() != v.out0 {
+			t.Errorf("got %v %v, want %v", out0, err, v.out0)
+		}
+	}
+	if _, err := byteman.ParseASN1Integer(nil); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.ParseASN1Int64([]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func BenchmarkASN1Reader(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		r := byteman.NewASN1Reader(berSequence)
+		for {
+			if _, err := r.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	z85Alphabet    = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+)
+
+// FromBase64 returns a byte slice by the given Base64 string and desired byte size.
+// Both standard and URL-safe alphabets are accepted, with or without padding.
+func FromBase64(str string, size int) ([]byte, error) {
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(str, "-_") {
+		enc = base64.RawURLEncoding
+	}
+	decoded, err := enc.DecodeString(strings.TrimRight(str, "="))
+	if err != nil {
+		return nil, corruptInputError(err, "base64")
+	}
+	return Resize(decoded, size), nil
+}
+
+// ToBase64 returns the padded standard Base64 string of the given byte slice and desired byte size.
+func ToBase64(b []byte, size int) string {
+	return base64.StdEncoding.EncodeToString(Resize(b, size))
+}
+
+// ToBase64URL returns the unpadded URL-safe Base64 string of the given byte slice and desired byte size.
+func ToBase64URL(b []byte, size int) string {
+	return base64.RawURLEncoding.EncodeToString(Resize(b, size))
+}
+
+// FromBase32 returns a byte slice by the given Base32 (RFC 4648) string and desired byte size.
+// Lowercase letters and missing padding are accepted.
+func FromBase32(str string, size int) ([]byte, error) {
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(strings.TrimRight(str, "=")))
+	if err != nil {
+		return nil, corruptInputError(err, "base32")
+	}
+	return Resize(decoded, size), nil
+}
+
+// ToBase32 returns the padded Base32 (RFC 4648) string of the given byte slice and desired byte size.
+func ToBase32(b []byte, size int) string {
+	return base32.StdEncoding.EncodeToString(Resize(b, size))
+}
+
+// FromBase58 returns a byte slice by the given Base58 (Bitcoin alphabet) string and desired byte size.
+func FromBase58(str string, size int) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	zeros := 0
+	for i := 0; i < len(str); i++ {
+		d := strings.IndexByte(base58Alphabet, str[i])
+		if d < 0 {
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("invalid base58 character %q", str[i])}
+		}
+		if d == 0 && zeros == i {
+			zeros++
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+	decoded := append(make([]byte, zeros), n.Bytes()...)
+	return Resize(decoded, size), nil
+}
+
+// ToBase58 returns the Base58 (Bitcoin alphabet) string of the given byte slice and desired byte size.
+func ToBase58(b []byte, size int) string {
+	b = Resize(b, size)
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// FromBase58Check returns a byte slice by the given Base58Check string and desired byte size.
+// The 4 byte double SHA-256 checksum is verified and removed.
+func FromBase58Check(str string, size int) ([]byte, error) {
+	decoded, err := FromBase58(str, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, &SyntaxError{Pos: len(str), Msg: "base58check data is too short"}
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	if !bytes.Equal(base58Checksum(payload), checksum) {
+		return nil, ErrChecksum
+	}
+	return Resize(payload, size), nil
+}
+
+// ToBase58Check returns the Base58Check string of the given byte slice and desired byte size.
+func ToBase58Check(b []byte, size int) string {
+	b = Resize(b, size)
+	return ToBase58(Combine(b, base58Checksum(b)), 0)
+}
+
+// base58Checksum returns the first 4 bytes of the double SHA-256 hash of the given byte slice.
+func base58Checksum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:4]
+}
+
+// FromAscii85 returns a byte slice by the given Ascii85 (Adobe) string and desired byte size.
+// The `<~` and `~>` delimiters, the `z` abbreviation and whitespace characters are accepted.
+func FromAscii85(str string, size int) ([]byte, error) {
+	src, first := str, 0
+	if strings.HasPrefix(src, "<~") {
+		src, first = src[2:], 2
+	}
+	src = strings.TrimSuffix(src, "~>")
+	decoded := make([]byte, 4*len(src))
+	n, _, err := ascii85.Decode(decoded, []byte(src), true)
+	if err != nil {
+		if e, ok := err.(ascii85.CorruptInputError); ok {
+			return nil, &SyntaxError{Pos: first + int(e), Msg: "invalid ascii85 data"}
+		}
+		return nil, err
+	}
+	return Resize(decoded[:n], size), nil
+}
+
+// ToAscii85 returns the Ascii85 (Adobe) string, without delimiters, of the given byte slice and desired byte size.
+func ToAscii85(b []byte, size int) string {
+	b = Resize(b, size)
+	out := make([]byte, ascii85.MaxEncodedLen(len(b)))
+	return string(out[:ascii85.Encode(out, b)])
+}
+
+// FromZ85 returns a byte slice by the given Z85 (ZeroMQ RFC 32) string and desired byte size.
+func FromZ85(str string, size int) ([]byte, error) {
+	if len(str)%5 != 0 {
+		return nil, &SyntaxError{Pos: len(str), Msg: "z85 string length must be a multiple of 5"}
+	}
+	decoded := make([]byte, 0, len(str)/5*4)
+	for i := 0; i < len(str); i += 5 {
+		var v uint64
+		for j := i; j < i+5; j++ {
+			d := strings.IndexByte(z85Alphabet, str[j])
+			if d < 0 {
+				return nil, &SyntaxError{Pos: j, Msg: fmt.Sprintf("invalid z85 character %q", str[j])}
+			}
+			v = v*85 + uint64(d)
+		}
+		if v > 0xffffffff {
+			return nil, &SyntaxError{Pos: i, Msg: "z85 group overflows 32 bits"}
+		}
+		decoded = append(decoded, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return Resize(decoded, size), nil
+}
+
+// ToZ85 returns the Z85 (ZeroMQ RFC 32) string of the given byte slice and desired byte size.
+// The resized byte slice length must be a multiple of 4.
+func ToZ85(b []byte, size int) (string, error) {
+	b = Resize(b, size)
+	if len(b)%4 != 0 {
+		return "", fmt.Errorf("byteman: z85 data length must be a multiple of 4, got %d", len(b))
+	}
+	out := make([]byte, 0, len(b)/4*5)
+	for i := 0; i < len(b); i += 4 {
+		v := uint32(b[i])<<24 | uint32(b[i+1])<<16 | uint32(b[i+2])<<8 | uint32(b[i+3])
+		var group [5]byte
+		for j := 4; j >= 0; j-- {
+			group[j] = z85Alphabet[v%85]
+			v /= 85
+		}
+		out = append(out, group[:]...)
+	}
+	return string(out), nil
+}
+
+// corruptInputError returns a *SyntaxError by the given encoding/base64 or encoding/base32 error.
+func corruptInputError(err error, name string) error {
+	switch e := err.(type) {
+	case base64.CorruptInputError:
+		return &SyntaxError{Pos: int(e), Msg: "invalid " + name + " data"}
+	case base32.CorruptInputError:
+		return &SyntaxError{Pos: int(e), Msg: "invalid " + name + " data"}
+	}
+	return err
+}
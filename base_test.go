@@ -0,0 +1,208 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestFromBase64(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"Zm9vYmFy", 0, []byte("foobar")},
+		{"Zm9vYg==", 0, []byte("foob")},
+		{"Zm9vYg", 0, []byte("foob")},
+		{"Zm9vYg", 2, []byte("fo")},
+		{"Zm9vYg", -1, []byte("foo")},
+		{"Zm9vYg", 5, []byte{0x66, 0x6f, 0x6f, 0x62, 0x00}},
+		{"-_8", 0, []byte{0xfb, 0xff}},
+		{"+/8=", 0, []byte{0xfb, 0xff}},
+		{"", 0, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.FromBase64(v.arg0, v.arg1)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if _, err := byteman.FromBase64("Zm9v!mFy", 0); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.SyntaxError); !ok || e.Pos != 4 {
+		t.Errorf("got %v, want position 4", err)
+	}
+}
+
+func TestToBase64(t *testing.T) {
+	if s := byteman.ToBase64([]byte("foob"), 0); s != "Zm9vYg==" {
+		t.Errorf("got %v, want %v", s, "Zm9vYg==")
+	}
+	if s := byteman.ToBase64([]byte("foob"), 3); s != "Zm9v" {
+		t.Errorf("got %v, want %v", s, "Zm9v")
+	}
+	if s := byteman.ToBase64URL([]byte{0xfb, 0xff}, 0); s != "-_8" {
+		t.Errorf("got %v, want %v", s, "-_8")
+	}
+}
+
+func BenchmarkFromBase64(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.FromBase64("Zm9vYmFy", 0)
+	}
+}
+
+func TestFromBase32(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"MZXW6YTBOI======", 0, []byte("foobar")},
+		{"MZXW6YTBOI", 0, []byte("foobar")},
+		{"mzxw6ytboi", 3, []byte("foo")},
+		{"MZXW6===", 4, []byte{0x66, 0x6f, 0x6f, 0x00}},
+		{"", 0, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.FromBase32(v.arg0, v.arg1)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if _, err := byteman.FromBase32("MZXW1", 0); err == nil {
+		t.Error("got nil, want error")
+	}
+	if s := byteman.ToBase32([]byte("foobar"), 0); s != "MZXW6YTBOI======" {
+		t.Errorf("got %v, want %v", s, "MZXW6YTBOI======")
+	}
+}
+
+func TestFromBase58(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"2NEpo7TZRRrLZSi2U", 0, []byte("Hello World!")},
+		{"11233QC4", 0, []byte{0x00, 0x00, 0x28, 0x7f, 0xb4, 0xcd}},
+		{"11233QC4", -4, []byte{0x00, 0x00}},
+		{"1", 0, []byte{0x00}},
+		{"", 0, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.FromBase58(v.arg0, v.arg1)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+		if v.arg1 == 0 {
+			if s := byteman.ToBase58(v.out, 0); s != v.arg0 {
+				t.Errorf("got %v, want %v", s, v.arg0)
+			}
+		}
+	}
+
+	if _, err := byteman.FromBase58("2NEpo0", 0); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.SyntaxError); !ok || e.Pos != 5 {
+		t.Errorf("got %v, want position 5", err)
+	}
+}
+
+func BenchmarkToBase58(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.ToBase58([]byte("Hello World!"), 0)
+	}
+}
+
+func TestFromBase58Check(t *testing.T) {
+	b, err := byteman.FromBase58Check("1111111111111111111114oLvT2", 0)
+	if err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if !bytes.Equal(b, make([]byte, 21)) {
+		t.Errorf("got %v, want %v", b, make([]byte, 21))
+	}
+	if s := byteman.ToBase58Check(make([]byte, 21), 0); s != "1111111111111111111114oLvT2" {
+		t.Errorf("got %v, want %v", s, "1111111111111111111114oLvT2")
+	}
+
+	s := byteman.ToBase58Check([]byte("foo"), 0)
+	if b, err := byteman.FromBase58Check(s, 0); err != nil || !bytes.Equal(b, []byte("foo")) {
+		t.Errorf("got %v, %v, want %v", b, err, []byte("foo"))
+	}
+	if _, err := byteman.FromBase58Check("1111111111111111111114oLvT3", 0); err != byteman.ErrChecksum {
+		t.Errorf("got %v, want %v", err, byteman.ErrChecksum)
+	}
+	if _, err := byteman.FromBase58Check("2", 0); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func TestFromAscii85(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"9jqo^", 0, []byte("Man ")},
+		{"<~9jqo^~>", 0, []byte("Man ")},
+		{"9jqo ^\nz", 0, []byte("Man \x00\x00\x00\x00")},
+		{"9jqo^", 2, []byte("Ma")},
+		{"", 0, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.FromAscii85(v.arg0, v.arg1)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if _, err := byteman.FromAscii85("<~9j{o^~>", 0); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.SyntaxError); !ok || e.Pos != 4 {
+		t.Errorf("got %v, want position 4", err)
+	}
+	if s := byteman.ToAscii85([]byte("Man \x00\x00\x00\x00"), 0); s != "9jqo^z" {
+		t.Errorf("got %v, want %v", s, "9jqo^z")
+	}
+}
+
+func TestFromZ85(t *testing.T) {
+	hello := []byte{0x86, 0x4f, 0xd2, 0x6f, 0xb5, 0x59, 0xf7, 0x5b}
+	b, err := byteman.FromZ85("HelloWorld", 0)
+	if err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if !bytes.Equal(b, hello) {
+		t.Errorf("got %v, want %v", b, hello)
+	}
+	if s, err := byteman.ToZ85(hello, 0); err != nil || s != "HelloWorld" {
+		t.Errorf("got %v, %v, want %v", s, err, "HelloWorld")
+	}
+	if s, err := byteman.ToZ85(hello, -4); err != nil || s != "Hello" {
+		t.Errorf("got %v, %v, want %v", s, err, "Hello")
+	}
+
+	if _, err := byteman.ToZ85(hello, 3); err == nil {
+		t.Error("got nil, want error")
+	}
+	for _, s := range []string{"Hell", "Hell\"", "#####"} {
+		if _, err := byteman.FromZ85(s, 0); err == nil {
+			t.Errorf("got nil, want error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import "io"
+
+// BitOrder represents the bit order within a byte.
+type BitOrder uint8
+
+const (
+	// BitOrderMSB represents the most significant bit first order.
+	BitOrderMSB BitOrder = 0
+	// BitOrderLSB represents the least significant bit first order (i.e. HDLC and UART transmission order).
+	BitOrderLSB BitOrder = 1
+)
+
+// BitReader represents a bit reader over a byte slice.
+type BitReader struct {
+	b     []byte
+	n     int
+	pos   int
+	order BitOrder
+}
+
+// NewBitReader returns a new bit reader by the given byte slice, number of bits and bit order.
+// A negative or too large number of bits means all bits of the byte slice.
+func NewBitReader(b []byte, n int, order BitOrder) *BitReader {
+	return &BitReader{b: b, n: bitCount(b, n), order: order}
+}
+
+// ReadBit returns the next bit (0 or 1). It returns io.EOF if there are no more bits.
+func (r *BitReader) ReadBit() (uint8, error) {
+	if r.pos >= r.n {
+		return 0, io.EOF
+	}
+	bit := getBit(r.b, r.pos, r.order)
+	r.pos++
+	return bit, nil
+}
+
+// ReadBits returns the next n bits (up to 64) as an unsigned integer, first bit being the most significant.
+// It returns io.EOF if there are no more bits and io.ErrUnexpectedEOF if there are less than n bits.
+func (r *BitReader) ReadBits(n int) (uint64, error) {
+	if n <= 0 || n > 64 {
+		return 0, nil
+	}
+	if r.pos >= r.n {
+		return 0, io.EOF
+	}
+	if r.n-r.pos < n {
+		return 0, io.ErrUnexpectedEOF
+	}
+	var v uint64
+	for i := 0; i < n; i++ {
+		v = v<<1 | uint64(getBit(r.b, r.pos, r.order))
+		r.pos++
+	}
+	return v, nil
+}
+
+// Len returns the number of unread bits.
+func (r *BitReader) Len() int {
+	return r.n - r.pos
+}
+
+// BitWriter represents a bit writer which builds a byte slice.
+type BitWriter struct {
+	b     []byte
+	n     int
+	order BitOrder
+}
+
+// NewBitWriter returns a new bit writer by the given bit order.
+func NewBitWriter(order BitOrder) *BitWriter {
+	return &BitWriter{order: order}
+}
+
+// WriteBit writes the given bit (non-zero values are written as 1).
+func (w *BitWriter) WriteBit(bit uint8) {
+	if w.n%8 == 0 {
+		w.b = append(w.b, 0)
+	}
+	if bit != 0 {
+		setBit(w.b, w.n, w.order)
+	}
+	w.n++
+}
+
+// WriteBits writes the lowest n bits (up to 64) of the given value, most significant bit first.
+func (w *BitWriter) WriteBits(v uint64, n int) {
+	for i := n - 1; i >= 0 && i < 64; i-- {
+		w.WriteBit(uint8(v >> uint(i) & 1))
+	}
+}
+
+// Bytes returns the written bits as a byte slice. The unused bits of the last byte are zero.
+func (w *BitWriter) Bytes() []byte {
+	return w.b
+}
+
+// Len returns the number of written bits.
+func (w *BitWriter) Len() int {
+	return w.n
+}
+
+// bitCount returns the number of bits by the given byte slice and desired number of bits.
+func bitCount(b []byte, n int) int {
+	if n < 0 || n > len(b)*8 {
+		return len(b) * 8
+	}
+	return n
+}
+
+// getBit returns the bit at the given position by the given bit order.
+func getBit(b []byte, i int, order BitOrder) uint8 {
+	if order == BitOrderLSB {
+		return b[i/8] >> uint(i%8) & 1
+	}
+	return b[i/8] >> uint(7-i%8) & 1
+}
+
+// setBit sets the bit at the given position by the given bit order.
+func setBit(b []byte, i int, order BitOrder) {
+	if order == BitOrderLSB {
+		b[i/8] |= 1 << uint(i%8)
+	} else {
+		b[i/8] |= 0x80 >> uint(i%8)
+	}
+}
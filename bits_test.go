@@ -0,0 +1,71 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestBitReader(t *testing.T) {
+	r := byteman.NewBitReader([]byte{0xa5, 0xf0}, 12, byteman.BitOrderMSB)
+	if v, err := r.ReadBits(4); err != nil || v != 0xa {
+		t.Errorf("got %v, %v, want %v", v, err, 0xa)
+	}
+	if b, err := r.ReadBit(); err != nil || b != 0 {
+		t.Errorf("got %v, %v, want %v", b, err, 0)
+	}
+	if v, err := r.ReadBits(5); err != nil || v != 0x17 {
+		t.Errorf("got %v, %v, want %v", v, err, 0x17)
+	}
+	if r.Len() != 2 {
+		t.Errorf("got %v, want %v", r.Len(), 2)
+	}
+	if _, err := r.ReadBits(3); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	r.ReadBits(2)
+	if _, err := r.ReadBit(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+
+	r = byteman.NewBitReader([]byte{0x01, 0x80}, -1, byteman.BitOrderLSB)
+	if v, err := r.ReadBits(16); err != nil || v != 0x8001 {
+		t.Errorf("got %#x, %v, want %#x", v, err, 0x8001)
+	}
+}
+
+func TestBitWriter(t *testing.T) {
+	table := []struct {
+		arg0 byteman.BitOrder
+		out  []byte
+	}{
+		{byteman.BitOrderMSB, []byte{0xed, 0x40}},
+		{byteman.BitOrderLSB, []byte{0xb7, 0x02}},
+	}
+	for _, v := range table {
+		w := byteman.NewBitWriter(v.arg0)
+		w.WriteBit(1)
+		w.WriteBits(0x1b, 5)
+		w.WriteBits(0x5, 4)
+		if w.Len() != 10 {
+			t.Errorf("got %v, want %v", w.Len(), 10)
+		}
+		if b := w.Bytes(); !bytes.Equal(b, v.out) {
+			t.Errorf("got %#v, want %#v", b, v.out)
+		}
+	}
+}
+
+func BenchmarkBitWriter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		w := byteman.NewBitWriter(byteman.BitOrderMSB)
+		for j := 0; j < 64; j++ {
+			w.WriteBits(0x5, 3)
+		}
+	}
+}
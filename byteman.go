@@ -4,9 +4,30 @@
 // Package byteman provides functions for bytes and bits.
 package byteman
 
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// ResizeOptions represents the resize options.
+// The zero value pads with zero bytes on the right and truncates from the right.
+type ResizeOptions struct {
+	// Fill is the padding byte (i.e. ' ' for mainframe fields, 0xff for flash images).
+	Fill byte
+	// PadLeft pads on the left which aligns the bytes to the right (i.e. numeric text).
+	PadLeft bool
+	// TruncateLeft truncates from the left which keeps the rightmost bytes.
+	TruncateLeft bool
+}
+
 // Combine combines the given byte slices.
+// The result is allocated once so no intermediate copies are left behind.
 func Combine(bs ...[]byte) []byte {
-	b := []byte{}
+	n := 0
+	for _, v := range bs {
+		n += len(v)
+	}
+	b := make([]byte, 0, n)
 	for _, v := range bs {
 		b = append(b, v...)
 	}
@@ -15,18 +36,229 @@ func Combine(bs ...[]byte) []byte {
 
 // Resize resizes the given byte slice.
 func Resize(b []byte, size int) []byte {
-	if size == 0 {
-		size = len(b)
-	} else if size < 0 {
-		if ns := len(b) + size; ns > 0 {
-			size = ns
+	return ResizeWith(b, size, ResizeOptions{})
+}
+
+// ResizeWith resizes the given byte slice by the given options.
+// The size argument has the same semantics as the Resize size argument.
+func ResizeWith(b []byte, size int, opts ResizeOptions) []byte {
+	size = resolveSize(len(b), size)
+	if len(b) > size {
+		if opts.TruncateLeft {
+			b = b[len(b)-size:]
 		} else {
-			size = 0
+			b = b[:size]
 		}
 	}
 	nb := make([]byte, size)
-	if b != nil {
+	pad := nb[len(b):]
+	if opts.PadLeft {
+		pad = nb[:size-len(b)]
+		copy(nb[size-len(b):], b)
+	} else {
 		copy(nb, b)
 	}
+	if opts.Fill != 0 {
+		for i := range pad {
+			pad[i] = opts.Fill
+		}
+	}
 	return nb
 }
+
+// bitOp represents a bitwise operation.
+type bitOp uint8
+
+const (
+	bitOpAnd bitOp = iota
+	bitOpOr
+	bitOpXor
+	bitOpAndNot
+)
+
+// And returns the bitwise AND of the given byte slices. It returns nil if the lengths differ.
+func And(a, b []byte) []byte {
+	return bitwiseNew(a, b, bitOpAnd)
+}
+
+// Or returns the bitwise OR of the given byte slices. It returns nil if the lengths differ.
+func Or(a, b []byte) []byte {
+	return bitwiseNew(a, b, bitOpOr)
+}
+
+// Xor returns the bitwise XOR of the given byte slices. It returns nil if the lengths differ.
+func Xor(a, b []byte) []byte {
+	return bitwiseNew(a, b, bitOpXor)
+}
+
+// AndNot returns the bitwise AND NOT (bit clear) of the given byte slices. It returns nil if the lengths differ.
+func AndNot(a, b []byte) []byte {
+	return bitwiseNew(a, b, bitOpAndNot)
+}
+
+// Not returns the bitwise NOT of the given byte slice.
+func Not(b []byte) []byte {
+	return NotInPlace(Resize(b, len(b)))
+}
+
+// AndInPlace sets dst to the bitwise AND of dst and src, and returns dst. It returns nil if the lengths differ.
+func AndInPlace(dst, src []byte) []byte {
+	return bitwiseInPlace(dst, src, bitOpAnd)
+}
+
+// OrInPlace sets dst to the bitwise OR of dst and src, and returns dst. It returns nil if the lengths differ.
+func OrInPlace(dst, src []byte) []byte {
+	return bitwiseInPlace(dst, src, bitOpOr)
+}
+
+// XorInPlace sets dst to the bitwise XOR of dst and src, and returns dst. It returns nil if the lengths differ.
+func XorInPlace(dst, src []byte) []byte {
+	return bitwiseInPlace(dst, src, bitOpXor)
+}
+
+// AndNotInPlace sets dst to the bitwise AND NOT of dst and src, and returns dst. It returns nil if the lengths differ.
+func AndNotInPlace(dst, src []byte) []byte {
+	return bitwiseInPlace(dst, src, bitOpAndNot)
+}
+
+// NotInPlace sets the given byte slice to its bitwise NOT and returns it.
+func NotInPlace(b []byte) []byte {
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		binary.LittleEndian.PutUint64(b[i:], ^binary.LittleEndian.Uint64(b[i:]))
+	}
+	for ; i < len(b); i++ {
+		b[i] = ^b[i]
+	}
+	return b
+}
+
+// AndKey returns the bitwise AND of the given byte slice and repeating key. It returns nil if the key is empty.
+func AndKey(b, key []byte) []byte {
+	return bitwiseKey(make([]byte, len(b)), b, key, bitOpAnd)
+}
+
+// OrKey returns the bitwise OR of the given byte slice and repeating key. It returns nil if the key is empty.
+func OrKey(b, key []byte) []byte {
+	return bitwiseKey(make([]byte, len(b)), b, key, bitOpOr)
+}
+
+// XorKey returns the bitwise XOR of the given byte slice and repeating key (i.e. keystream). It returns nil if the key is empty.
+func XorKey(b, key []byte) []byte {
+	return bitwiseKey(make([]byte, len(b)), b, key, bitOpXor)
+}
+
+// AndNotKey returns the bitwise AND NOT of the given byte slice and repeating key. It returns nil if the key is empty.
+func AndNotKey(b, key []byte) []byte {
+	return bitwiseKey(make([]byte, len(b)), b, key, bitOpAndNot)
+}
+
+// AndKeyInPlace sets the given byte slice to its bitwise AND with the repeating key and returns it.
+// It returns nil if the key is empty.
+func AndKeyInPlace(b, key []byte) []byte {
+	return bitwiseKey(b, b, key, bitOpAnd)
+}
+
+// OrKeyInPlace sets the given byte slice to its bitwise OR with the repeating key and returns it.
+// It returns nil if the key is empty.
+func OrKeyInPlace(b, key []byte) []byte {
+	return bitwiseKey(b, b, key, bitOpOr)
+}
+
+// XorKeyInPlace sets the given byte slice to its bitwise XOR with the repeating key and returns it.
+// It returns nil if the key is empty.
+func XorKeyInPlace(b, key []byte) []byte {
+	return bitwiseKey(b, b, key, bitOpXor)
+}
+
+// AndNotKeyInPlace sets the given byte slice to its bitwise AND NOT with the repeating key and returns it.
+// It returns nil if the key is empty.
+func AndNotKeyInPlace(b, key []byte) []byte {
+	return bitwiseKey(b, b, key, bitOpAndNot)
+}
+
+// bitwiseNew returns a new byte slice by the given bitwise operation.
+func bitwiseNew(a, b []byte, op bitOp) []byte {
+	if len(a) != len(b) {
+		return nil
+	}
+	dst := make([]byte, len(a))
+	bitwise(dst, a, b, op)
+	return dst
+}
+
+// bitwiseInPlace applies the given bitwise operation to dst.
+func bitwiseInPlace(dst, src []byte, op bitOp) []byte {
+	if len(dst) != len(src) {
+		return nil
+	}
+	bitwise(dst, dst, src, op)
+	return dst
+}
+
+// bitwiseKey applies the given bitwise operation with the repeating key to dst.
+func bitwiseKey(dst, b, key []byte, op bitOp) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+	// Repeat the key so each chunk is a multiple of the word size.
+	chunk := key
+	if len(b) > len(key) && len(key)%8 != 0 {
+		chunk = bytes.Repeat(key, 8)
+	}
+	for i := 0; i < len(b); i += len(chunk) {
+		end := i + len(chunk)
+		if end > len(b) {
+			end = len(b)
+		}
+		bitwise(dst[i:end], b[i:end], chunk[:end-i], op)
+	}
+	return dst
+}
+
+// bitwise sets dst to the given bitwise operation of a and b, processing a word at a time.
+// The byte slices must have the same length.
+func bitwise(dst, a, b []byte, op bitOp) {
+	i := 0
+	for ; i+8 <= len(dst); i += 8 {
+		x, y := binary.LittleEndian.Uint64(a[i:]), binary.LittleEndian.Uint64(b[i:])
+		var z uint64
+		switch op {
+		case bitOpAnd:
+			z = x & y
+		case bitOpOr:
+			z = x | y
+		case bitOpXor:
+			z = x ^ y
+		default:
+			z = x &^ y
+		}
+		binary.LittleEndian.PutUint64(dst[i:], z)
+	}
+	for ; i < len(dst); i++ {
+		switch op {
+		case bitOpAnd:
+			dst[i] = a[i] & b[i]
+		case bitOpOr:
+			dst[i] = a[i] | b[i]
+		case bitOpXor:
+			dst[i] = a[i] ^ b[i]
+		default:
+			dst[i] = a[i] &^ b[i]
+		}
+	}
+}
+
+// resolveSize returns the desired byte size by the given length and size argument.
+// Zero means the given length and negative values are relative to the given length.
+func resolveSize(n, size int) int {
+	if size == 0 {
+		return n
+	} else if size < 0 {
+		if ns := n + size; ns > 0 {
+			return ns
+		}
+		return 0
+	}
+	return size
+}
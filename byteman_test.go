@@ -67,3 +67,142 @@ func BenchmarkResize(b *testing.B) {
 		byteman.Resize([]byte("a"), 1)
 	}
 }
+
+func TestResizeWith(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.ResizeOptions
+		out  []byte
+	}{
+		{[]byte("foo"), 0, byteman.ResizeOptions{}, []byte("foo")},
+		{[]byte("foo"), 5, byteman.ResizeOptions{}, []byte{0x66, 0x6f, 0x6f, 0x00, 0x00}},
+		{[]byte("foo"), 5, byteman.ResizeOptions{Fill: ' '}, []byte("foo  ")},
+		{[]byte("foo"), 5, byteman.ResizeOptions{Fill: 0xff}, []byte{0x66, 0x6f, 0x6f, 0xff, 0xff}},
+		{[]byte("42"), 5, byteman.ResizeOptions{Fill: '0', PadLeft: true}, []byte("00042")},
+		{[]byte("42"), 4, byteman.ResizeOptions{PadLeft: true}, []byte{0x00, 0x00, 0x34, 0x32}},
+		{[]byte("foobar"), 3, byteman.ResizeOptions{}, []byte("foo")},
+		{[]byte("foobar"), 3, byteman.ResizeOptions{TruncateLeft: true}, []byte("bar")},
+		{[]byte("foobar"), -2, byteman.ResizeOptions{TruncateLeft: true}, []byte("obar")},
+		{[]byte("foobar"), -7, byteman.ResizeOptions{TruncateLeft: true}, []byte{}},
+		{[]byte("foobar"), 3, byteman.ResizeOptions{Fill: ' ', PadLeft: true}, []byte("foo")},
+		{nil, 2, byteman.ResizeOptions{Fill: ' '}, []byte("  ")},
+	}
+	for _, v := range table {
+		b := byteman.ResizeWith(v.arg0, v.arg1, v.arg2)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+}
+
+func BenchmarkResizeWith(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.ResizeWith([]byte("a"), 4, byteman.ResizeOptions{Fill: ' ', PadLeft: true})
+	}
+}
+
+func TestBitwise(t *testing.T) {
+	a := []byte{0x0f, 0xf0, 0xaa, 0x55, 0x00, 0xff, 0x12, 0x34, 0x56}
+	b := []byte{0xff, 0x0f, 0x55, 0x55, 0xff, 0x00, 0xf0, 0x0f, 0x66}
+	table := []struct {
+		fn  func(a, b []byte) []byte
+		out []byte
+	}{
+		{byteman.And, []byte{0x0f, 0x00, 0x00, 0x55, 0x00, 0x00, 0x10, 0x04, 0x46}},
+		{byteman.Or, []byte{0xff, 0xff, 0xff, 0x55, 0xff, 0xff, 0xf2, 0x3f, 0x76}},
+		{byteman.Xor, []byte{0xf0, 0xff, 0xff, 0x00, 0xff, 0xff, 0xe2, 0x3b, 0x30}},
+		{byteman.AndNot, []byte{0x00, 0xf0, 0xaa, 0x00, 0x00, 0xff, 0x02, 0x30, 0x10}},
+	}
+	for _, v := range table {
+		if r := v.fn(a, b); !bytes.Equal(r, v.out) {
+			t.Errorf("got %v, want %v", r, v.out)
+		}
+		if r := v.fn(a, b[:2]); r != nil {
+			t.Errorf("got %v, want nil", r)
+		}
+	}
+
+	inPlace := []struct {
+		fn  func(dst, src []byte) []byte
+		out []byte
+	}{
+		{byteman.AndInPlace, table[0].out},
+		{byteman.OrInPlace, table[1].out},
+		{byteman.XorInPlace, table[2].out},
+		{byteman.AndNotInPlace, table[3].out},
+	}
+	for _, v := range inPlace {
+		dst := byteman.Resize(a, 0)
+		if r := v.fn(dst, b); !bytes.Equal(r, v.out) || !bytes.Equal(dst, v.out) {
+			t.Errorf("got %v, want %v", dst, v.out)
+		}
+		if r := v.fn(dst, b[:2]); r != nil {
+			t.Errorf("got %v, want nil", r)
+		}
+	}
+
+	not := []byte{0xf0, 0x0f, 0x55, 0xaa, 0xff, 0x00, 0xed, 0xcb, 0xa9}
+	if r := byteman.Not(a); !bytes.Equal(r, not) {
+		t.Errorf("got %v, want %v", r, not)
+	}
+	if dst := byteman.Resize(a, 0); !bytes.Equal(byteman.NotInPlace(dst), not) || !bytes.Equal(dst, not) {
+		t.Errorf("got %v, want %v", dst, not)
+	}
+}
+
+func BenchmarkXor(b *testing.B) {
+	x, y := bytes.Repeat([]byte("a"), 1024), bytes.Repeat([]byte("b"), 1024)
+	for i := 0; i < b.N; i++ {
+		byteman.Xor(x, y)
+	}
+}
+
+func TestBitwiseKey(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	for _, key := range [][]byte{{0x20}, []byte("key"), []byte("01234567"), bytes.Repeat([]byte("k"), 64)} {
+		want := make([]byte, len(data))
+		for i := range data {
+			want[i] = data[i] ^ key[i%len(key)]
+		}
+		if r := byteman.XorKey(data, key); !bytes.Equal(r, want) {
+			t.Errorf("got %v, want %v", r, want)
+		}
+		dst := byteman.Resize(data, 0)
+		if r := byteman.XorKeyInPlace(dst, key); !bytes.Equal(r, want) || !bytes.Equal(dst, want) {
+			t.Errorf("got %v, want %v", dst, want)
+		}
+		if r := byteman.XorKeyInPlace(dst, key); !bytes.Equal(r, data) {
+			t.Errorf("got %v, want %v", r, data)
+		}
+	}
+
+	b := []byte{0xff, 0x0f, 0xf0, 0x00}
+	key := []byte{0x0f, 0xf0}
+	table := []struct {
+		fn  func(b, key []byte) []byte
+		out []byte
+	}{
+		{byteman.AndKey, []byte{0x0f, 0x00, 0x00, 0x00}},
+		{byteman.OrKey, []byte{0xff, 0xff, 0xff, 0xf0}},
+		{byteman.AndNotKey, []byte{0xf0, 0x0f, 0xf0, 0x00}},
+		{byteman.AndKeyInPlace, []byte{0x0f, 0x00, 0x00, 0x00}},
+		{byteman.OrKeyInPlace, []byte{0xff, 0xff, 0xff, 0xf0}},
+		{byteman.AndNotKeyInPlace, []byte{0xf0, 0x0f, 0xf0, 0x00}},
+	}
+	for _, v := range table {
+		if r := v.fn(byteman.Resize(b, 0), key); !bytes.Equal(r, v.out) {
+			t.Errorf("got %v, want %v", r, v.out)
+		}
+		if r := v.fn(b, nil); r != nil {
+			t.Errorf("got %v, want nil", r)
+		}
+	}
+}
+
+func BenchmarkXorKey(b *testing.B) {
+	x := bytes.Repeat([]byte("a"), 1024)
+	for i := 0; i < b.N; i++ {
+		byteman.XorKey(x, []byte("key"))
+	}
+}
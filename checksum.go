@@ -0,0 +1,345 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"hash"
+	"hash/adler32"
+)
+
+// InternetChecksum returns the RFC 1071 Internet checksum (16 bit ones' complement of the ones' complement sum) of the given byte slice.
+func InternetChecksum(b []byte) uint16 {
+	d := &internetDigest{}
+	d.Write(b)
+	return uint16(d.Sum32())
+}
+
+// NewInternetChecksum returns a new streaming RFC 1071 Internet checksum hash.
+func NewInternetChecksum() hash.Hash32 {
+	return &internetDigest{}
+}
+
+// UpdateInternetChecksum returns the RFC 1071 Internet checksum incrementally updated (RFC 1624) by the given old and new 16 bit field values.
+func UpdateInternetChecksum(sum, oldValue, newValue uint16) uint16 {
+	s := uint32(^sum) + uint32(^oldValue) + uint32(newValue)
+	return ^fold16(s)
+}
+
+// Fletcher16 returns the Fletcher-16 checksum of the given byte slice.
+func Fletcher16(b []byte) uint16 {
+	d := newFletcher(1, nil)
+	d.Write(b)
+	return uint16(d.Sum64())
+}
+
+// NewFletcher16 returns a new streaming Fletcher-16 hash.
+func NewFletcher16() hash.Hash32 {
+	return newFletcher(1, nil)
+}
+
+// Fletcher32 returns the Fletcher-32 checksum of the given byte slice by the given byte order (endianness) of the 16 bit words.
+// An incomplete trailing word is padded with zero.
+func Fletcher32(b []byte, bo ByteOrder) uint32 {
+	d := newFletcher(2, bo)
+	d.Write(b)
+	return uint32(d.Sum64())
+}
+
+// NewFletcher32 returns a new streaming Fletcher-32 hash by the given byte order (endianness) of the 16 bit words.
+func NewFletcher32(bo ByteOrder) hash.Hash32 {
+	return newFletcher(2, bo)
+}
+
+// Fletcher64 returns the Fletcher-64 checksum of the given byte slice by the given byte order (endianness) of the 32 bit words.
+// An incomplete trailing word is padded with zero.
+func Fletcher64(b []byte, bo ByteOrder) uint64 {
+	d := newFletcher(4, bo)
+	d.Write(b)
+	return d.Sum64()
+}
+
+// NewFletcher64 returns a new streaming Fletcher-64 hash by the given byte order (endianness) of the 32 bit words.
+func NewFletcher64(bo ByteOrder) hash.Hash64 {
+	return newFletcher(4, bo)
+}
+
+// Adler32 returns the Adler-32 checksum of the given byte slice.
+func Adler32(b []byte) uint32 {
+	return adler32.Checksum(b)
+}
+
+// NewAdler32 returns a new streaming Adler-32 hash.
+func NewAdler32() hash.Hash32 {
+	return adler32.New()
+}
+
+// LRC returns the Modbus longitudinal redundancy check (two's complement of the 8 bit sum) of the given byte slice.
+// For Modbus ASCII frames it must be computed over the decoded bytes.
+func LRC(b []byte) byte {
+	return -Sum8(b)
+}
+
+// NewLRC returns a new streaming Modbus LRC hash.
+func NewLRC() hash.Hash32 {
+	return &byteDigest{op: byteOpLRC}
+}
+
+// Sum8 returns the 8 bit (modulo 256) sum of the given byte slice.
+func Sum8(b []byte) byte {
+	var s byte
+	for _, v := range b {
+		s += v
+	}
+	return s
+}
+
+// NewSum8 returns a new streaming 8 bit sum hash.
+func NewSum8() hash.Hash32 {
+	return &byteDigest{op: byteOpSum}
+}
+
+// Xor8 returns the XOR of all bytes of the given byte slice.
+func Xor8(b []byte) byte {
+	var s byte
+	for _, v := range b {
+		s ^= v
+	}
+	return s
+}
+
+// NewXor8 returns a new streaming XOR hash.
+func NewXor8() hash.Hash32 {
+	return &byteDigest{op: byteOpXor}
+}
+
+// SumZeroed resets the given hash, writes the given byte slice by treating the size bytes at the given offset
+// (e.g. the checksum field) as zero and returns the hash sum. The byte slice is not modified.
+// It returns nil if the field is out of range.
+func SumZeroed(h hash.Hash, b []byte, offset, size int) []byte {
+	if offset < 0 || size < 0 || offset > len(b) || size > len(b)-offset {
+		return nil
+	}
+	h.Reset()
+	h.Write(b[:offset])
+	var zeros [64]byte
+	for n := size; n > 0; {
+		c := n
+		if c > len(zeros) {
+			c = len(zeros)
+		}
+		h.Write(zeros[:c])
+		n -= c
+	}
+	h.Write(b[offset+size:])
+	return h.Sum(nil)
+}
+
+// fold16 returns the given ones' complement sum folded to 16 bits.
+func fold16(s uint32) uint16 {
+	for s > 0xffff {
+		s = s&0xffff + s>>16
+	}
+	return uint16(s)
+}
+
+// internetDigest represents a streaming RFC 1071 Internet checksum.
+type internetDigest struct {
+	sum  uint32
+	odd  bool
+	last byte
+}
+
+// Write updates the checksum by the given byte slice.
+func (d *internetDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	if d.odd && len(p) > 0 {
+		d.sum += uint32(d.last)<<8 | uint32(p[0])
+		d.odd, p = false, p[1:]
+	}
+	for len(p) >= 2 {
+		d.sum += uint32(p[0])<<8 | uint32(p[1])
+		d.sum = uint32(fold16(d.sum))
+		p = p[2:]
+	}
+	if len(p) == 1 {
+		d.odd, d.last = true, p[0]
+	}
+	return n, nil
+}
+
+// Sum appends the current checksum in big-endian byte order to the given byte slice.
+func (d *internetDigest) Sum(in []byte) []byte {
+	s := d.Sum32()
+	return append(in, byte(s>>8), byte(s))
+}
+
+// Sum32 returns the current checksum.
+func (d *internetDigest) Sum32() uint32 {
+	s := d.sum
+	if d.odd {
+		s += uint32(d.last) << 8
+	}
+	return uint32(^fold16(s))
+}
+
+// Reset resets the checksum.
+func (d *internetDigest) Reset() {
+	*d = internetDigest{}
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *internetDigest) Size() int {
+	return 2
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *internetDigest) BlockSize() int {
+	return 2
+}
+
+// fletcherDigest represents a streaming Fletcher checksum.
+type fletcherDigest struct {
+	word    int
+	mod     uint64
+	bo      ByteOrder
+	sum1    uint64
+	sum2    uint64
+	pending []byte
+}
+
+// newFletcher returns a new Fletcher checksum by the given word size (1, 2 or 4 bytes) and byte order.
+func newFletcher(word int, bo ByteOrder) *fletcherDigest {
+	return &fletcherDigest{word: word, mod: 1<<(8*uint(word)) - 1, bo: bo, pending: make([]byte, 0, word)}
+}
+
+// Write updates the checksum by the given byte slice.
+func (d *fletcherDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(d.pending) > 0 {
+		c := d.word - len(d.pending)
+		if c > len(p) {
+			c = len(p)
+		}
+		d.pending, p = append(d.pending, p[:c]...), p[c:]
+		if len(d.pending) < d.word {
+			return n, nil
+		}
+		d.sum1, d.sum2 = d.add(d.sum1, d.sum2, d.pending)
+		d.pending = d.pending[:0]
+	}
+	sum1, sum2 := d.sum1, d.sum2
+	for len(p) >= d.word {
+		sum1, sum2 = d.add(sum1, sum2, p[:d.word])
+		p = p[d.word:]
+	}
+	d.sum1, d.sum2 = sum1, sum2
+	d.pending = append(d.pending, p...)
+	return n, nil
+}
+
+// add returns the sums updated by the given word.
+func (d *fletcherDigest) add(sum1, sum2 uint64, w []byte) (uint64, uint64) {
+	var v uint64
+	switch d.word {
+	case 1:
+		v = uint64(w[0])
+	case 2:
+		v = uint64(Uint16(w, d.bo))
+	default:
+		v = uint64(Uint32(w, d.bo))
+	}
+	sum1 = (sum1 + v) % d.mod
+	sum2 = (sum2 + sum1) % d.mod
+	return sum1, sum2
+}
+
+// Sum appends the current checksum in big-endian byte order to the given byte slice.
+func (d *fletcherDigest) Sum(in []byte) []byte {
+	b := FromUint(d.Sum64(), &BigEndian{})
+	return append(in, b[8-d.Size():]...)
+}
+
+// Sum32 returns the current checksum.
+func (d *fletcherDigest) Sum32() uint32 {
+	return uint32(d.Sum64())
+}
+
+// Sum64 returns the current checksum.
+func (d *fletcherDigest) Sum64() uint64 {
+	sum1, sum2 := d.sum1, d.sum2
+	if len(d.pending) > 0 {
+		w := make([]byte, d.word)
+		copy(w, d.pending)
+		sum1, sum2 = d.add(sum1, sum2, w)
+	}
+	return sum2<<(8*uint(d.word)) | sum1
+}
+
+// Reset resets the checksum.
+func (d *fletcherDigest) Reset() {
+	d.sum1, d.sum2, d.pending = 0, 0, d.pending[:0]
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *fletcherDigest) Size() int {
+	return 2 * d.word
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *fletcherDigest) BlockSize() int {
+	return d.word
+}
+
+// byteOp represents a byte checksum operation.
+type byteOp uint8
+
+const (
+	byteOpSum byteOp = 0
+	byteOpLRC byteOp = 1
+	byteOpXor byteOp = 2
+)
+
+// byteDigest represents a streaming 8 bit checksum.
+type byteDigest struct {
+	op  byteOp
+	sum byte
+}
+
+// Write updates the checksum by the given byte slice.
+func (d *byteDigest) Write(p []byte) (int, error) {
+	if d.op == byteOpXor {
+		d.sum ^= Xor8(p)
+	} else {
+		d.sum += Sum8(p)
+	}
+	return len(p), nil
+}
+
+// Sum appends the current checksum to the given byte slice.
+func (d *byteDigest) Sum(in []byte) []byte {
+	return append(in, byte(d.Sum32()))
+}
+
+// Sum32 returns the current checksum.
+func (d *byteDigest) Sum32() uint32 {
+	if d.op == byteOpLRC {
+		return uint32(-d.sum)
+	}
+	return uint32(d.sum)
+}
+
+// Reset resets the checksum.
+func (d *byteDigest) Reset() {
+	d.sum = 0
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *byteDigest) Size() int {
+	return 1
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *byteDigest) BlockSize() int {
+	return 1
+}
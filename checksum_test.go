@@ -0,0 +1,159 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestInternetChecksum(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out  uint16
+	}{
+		{[]byte{0x00, 0x01, 0xf2, 0x03, 0xf4, 0xf5, 0xf6, 0xf7}, 0x220d},
+		{[]byte{0x00, 0x01, 0xf2}, 0x0dfe},
+		{[]byte{}, 0xffff},
+	}
+	for _, v := range table {
+		if s := byteman.InternetChecksum(v.arg0); s != v.out {
+			t.Errorf("got %#04x, want %#04x", s, v.out)
+		}
+	}
+
+	// IPv4 header with the checksum field (offset 10) set.
+	header := []byte{0x45, 0x00, 0x00, 0x73, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11, 0xb8, 0x61, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0xc7}
+	if s := byteman.SumZeroed(byteman.NewInternetChecksum(), header, 10, 2); !bytes.Equal(s, []byte{0xb8, 0x61}) {
+		t.Errorf("got %v, want %v", s, []byte{0xb8, 0x61})
+	}
+	if s := byteman.InternetChecksum(header); s != 0 {
+		t.Errorf("got %#04x, want 0", s)
+	}
+
+	// Streaming with odd sized writes.
+	h := byteman.NewInternetChecksum()
+	for _, b := range header {
+		h.Write([]byte{b})
+	}
+	if s := h.Sum32(); s != 0 {
+		t.Errorf("got %#04x, want 0", s)
+	}
+
+	// Incremental update of the TTL/protocol word.
+	updated := append([]byte{}, header...)
+	updated[8] = 0x3f
+	sum := byteman.UpdateInternetChecksum(0xb861, 0x4011, 0x3f11)
+	if want := byteman.InternetChecksum(byteman.Combine(updated[:10], []byte{0, 0}, updated[12:])); sum != want {
+		t.Errorf("got %#04x, want %#04x", sum, want)
+	}
+}
+
+func BenchmarkInternetChecksum(b *testing.B) {
+	data := make([]byte, 1500)
+	for i := 0; i < b.N; i++ {
+		byteman.InternetChecksum(data)
+	}
+}
+
+func TestFletcher(t *testing.T) {
+	table := []struct {
+		arg0 string
+		out0 uint16
+		out1 uint32
+		out2 uint64
+	}{
+		{"abcde", 0xc8f0, 0xf04fc729, 0xc8c6c527646362c6},
+		{"abcdef", 0x2057, 0x56502d2a, 0xc8c72b276463c8c6},
+		{"abcdefgh", 0x0627, 0xebe19591, 0x312e2b28cccac8c6},
+	}
+	le := &byteman.LittleEndian{}
+	for _, v := range table {
+		if s := byteman.Fletcher16([]byte(v.arg0)); s != v.out0 {
+			t.Errorf("got %#x, want %#x", s, v.out0)
+		}
+		if s := byteman.Fletcher32([]byte(v.arg0), le); s != v.out1 {
+			t.Errorf("got %#x, want %#x", s, v.out1)
+		}
+		if s := byteman.Fletcher64([]byte(v.arg0), le); s != v.out2 {
+			t.Errorf("got %#x, want %#x", s, v.out2)
+		}
+
+		h := byteman.NewFletcher64(le)
+		for i := 0; i < len(v.arg0); i += 3 {
+			end := i + 3
+			if end > len(v.arg0) {
+				end = len(v.arg0)
+			}
+			h.Write([]byte(v.arg0[i:end]))
+		}
+		if s := h.Sum64(); s != v.out2 {
+			t.Errorf("got %#x, want %#x", s, v.out2)
+		}
+	}
+
+	if s := byteman.NewFletcher16().Sum([]byte{0xaa}); !bytes.Equal(s, []byte{0xaa, 0x00, 0x00}) {
+		t.Errorf("got %v, want %v", s, []byte{0xaa, 0x00, 0x00})
+	}
+	if s := byteman.Fletcher32([]byte{0x01, 0x02}, &byteman.BigEndian{}); s != 0x01020102 {
+		t.Errorf("got %#x, want %#x", s, 0x01020102)
+	}
+}
+
+func BenchmarkFletcher32(b *testing.B) {
+	data := make([]byte, 1500)
+	for i := 0; i < b.N; i++ {
+		byteman.Fletcher32(data, &byteman.LittleEndian{})
+	}
+}
+
+func TestAdler32(t *testing.T) {
+	if s := byteman.Adler32([]byte("Wikipedia")); s != 0x11e60398 {
+		t.Errorf("got %#x, want %#x", s, 0x11e60398)
+	}
+	h := byteman.NewAdler32()
+	h.Write([]byte("Wiki"))
+	h.Write([]byte("pedia"))
+	if s := h.Sum32(); s != 0x11e60398 {
+		t.Errorf("got %#x, want %#x", s, 0x11e60398)
+	}
+}
+
+func TestByteChecksums(t *testing.T) {
+	frame := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0a}
+	table := []struct {
+		arg0 func([]byte) byte
+		arg1 hash.Hash32
+		out  byte
+	}{
+		{byteman.LRC, byteman.NewLRC(), 0xf2},
+		{byteman.Sum8, byteman.NewSum8(), 0x0e},
+		{byteman.Xor8, byteman.NewXor8(), 0x08},
+	}
+	for _, v := range table {
+		if s := v.arg0(frame); s != v.out {
+			t.Errorf("got %#02x, want %#02x", s, v.out)
+		}
+		v.arg1.Write(frame[:2])
+		v.arg1.Write(frame[2:])
+		if s := v.arg1.Sum(nil); !bytes.Equal(s, []byte{v.out}) {
+			t.Errorf("got %v, want %v", s, []byte{v.out})
+		}
+	}
+
+	// The LRC of a frame including its LRC is zero.
+	if s := byteman.Sum8(append(frame, 0xf2)); s != 0 {
+		t.Errorf("got %#02x, want 0", s)
+	}
+	withLRC := append(append([]byte{}, frame...), 0x55)
+	if s := byteman.SumZeroed(byteman.NewLRC(), withLRC, 6, 1); !bytes.Equal(s, []byte{0xf2}) {
+		t.Errorf("got %v, want %v", s, []byte{0xf2})
+	}
+	if s := byteman.SumZeroed(byteman.NewLRC(), withLRC, 6, 2); s != nil {
+		t.Errorf("got %v, want nil", s)
+	}
+}
@@ -0,0 +1,257 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math/bits"
+)
+
+// CRCModel represents a CRC model by the Rocksoft parameters.
+type CRCModel struct {
+	// Name is the model name.
+	Name string
+	// Width is the CRC width in bits (3 to 64).
+	Width uint
+	// Poly is the generator polynomial without the top bit (normal representation).
+	Poly uint64
+	// Init is the initial register value.
+	Init uint64
+	// RefIn reflects the input bytes.
+	RefIn bool
+	// RefOut reflects the final register value.
+	RefOut bool
+	// XorOut is the value which is XORed with the final register value.
+	XorOut uint64
+	// Check is the CRC of the ASCII string "123456789".
+	Check uint64
+}
+
+var (
+	// CRC3GSM represents the CRC-3/GSM model.
+	CRC3GSM = CRCModel{Name: "CRC-3/GSM", Width: 3, Poly: 0x3, Init: 0x0, XorOut: 0x7, Check: 0x4}
+	// CRC4G704 represents the CRC-4/G-704 (CRC-4/ITU) model.
+	CRC4G704 = CRCModel{Name: "CRC-4/G-704", Width: 4, Poly: 0x3, Init: 0x0, RefIn: true, RefOut: true, XorOut: 0x0, Check: 0x7}
+	// CRC5USB represents the CRC-5/USB model.
+	CRC5USB = CRCModel{Name: "CRC-5/USB", Width: 5, Poly: 0x05, Init: 0x1f, RefIn: true, RefOut: true, XorOut: 0x1f, Check: 0x19}
+	// CRC7MMC represents the CRC-7/MMC model.
+	CRC7MMC = CRCModel{Name: "CRC-7/MMC", Width: 7, Poly: 0x09, Init: 0x00, XorOut: 0x00, Check: 0x75}
+	// CRC8SMBUS represents the CRC-8/SMBUS model.
+	CRC8SMBUS = CRCModel{Name: "CRC-8/SMBUS", Width: 8, Poly: 0x07, Init: 0x00, XorOut: 0x00, Check: 0xf4}
+	// CRC8MAXIMDOW represents the CRC-8/MAXIM-DOW (Dallas 1-Wire) model.
+	CRC8MAXIMDOW = CRCModel{Name: "CRC-8/MAXIM-DOW", Width: 8, Poly: 0x31, Init: 0x00, RefIn: true, RefOut: true, XorOut: 0x00, Check: 0xa1}
+	// CRC10ATM represents the CRC-10/ATM model.
+	CRC10ATM = CRCModel{Name: "CRC-10/ATM", Width: 10, Poly: 0x233, Init: 0x000, XorOut: 0x000, Check: 0x199}
+	// CRC15CAN represents the CRC-15/CAN model.
+	CRC15CAN = CRCModel{Name: "CRC-15/CAN", Width: 15, Poly: 0x4599, Init: 0x0000, XorOut: 0x0000, Check: 0x059e}
+	// CRC16ARC represents the CRC-16/ARC model.
+	CRC16ARC = CRCModel{Name: "CRC-16/ARC", Width: 16, Poly: 0x8005, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0xbb3d}
+	// CRC16MODBUS represents the CRC-16/MODBUS model.
+	CRC16MODBUS = CRCModel{Name: "CRC-16/MODBUS", Width: 16, Poly: 0x8005, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x4b37}
+	// CRC16CCITTFALSE represents the CRC-16/IBM-3740 (CRC-16/CCITT-FALSE) model.
+	CRC16CCITTFALSE = CRCModel{Name: "CRC-16/IBM-3740", Width: 16, Poly: 0x1021, Init: 0xffff, XorOut: 0x0000, Check: 0x29b1}
+	// CRC16XMODEM represents the CRC-16/XMODEM model.
+	CRC16XMODEM = CRCModel{Name: "CRC-16/XMODEM", Width: 16, Poly: 0x1021, Init: 0x0000, XorOut: 0x0000, Check: 0x31c3}
+	// CRC16KERMIT represents the CRC-16/KERMIT (CRC-16/CCITT) model.
+	CRC16KERMIT = CRCModel{Name: "CRC-16/KERMIT", Width: 16, Poly: 0x1021, Init: 0x0000, RefIn: true, RefOut: true, XorOut: 0x0000, Check: 0x2189}
+	// CRC16X25 represents the CRC-16/IBM-SDLC (CRC-16/X-25) model.
+	CRC16X25 = CRCModel{Name: "CRC-16/IBM-SDLC", Width: 16, Poly: 0x1021, Init: 0xffff, RefIn: true, RefOut: true, XorOut: 0xffff, Check: 0x906e}
+	// CRC24OPENPGP represents the CRC-24/OPENPGP model.
+	CRC24OPENPGP = CRCModel{Name: "CRC-24/OPENPGP", Width: 24, Poly: 0x864cfb, Init: 0xb704ce, XorOut: 0x000000, Check: 0x21cf02}
+	// CRC32 represents the CRC-32/ISO-HDLC (Ethernet, zlib, PNG) model.
+	CRC32 = CRCModel{Name: "CRC-32/ISO-HDLC", Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0xcbf43926}
+	// CRC32C represents the CRC-32/ISCSI (Castagnoli) model.
+	CRC32C = CRCModel{Name: "CRC-32/ISCSI", Width: 32, Poly: 0x1edc6f41, Init: 0xffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffff, Check: 0xe3069283}
+	// CRC32BZIP2 represents the CRC-32/BZIP2 model.
+	CRC32BZIP2 = CRCModel{Name: "CRC-32/BZIP2", Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, XorOut: 0xffffffff, Check: 0xfc891918}
+	// CRC32MPEG2 represents the CRC-32/MPEG-2 model.
+	CRC32MPEG2 = CRCModel{Name: "CRC-32/MPEG-2", Width: 32, Poly: 0x04c11db7, Init: 0xffffffff, XorOut: 0x00000000, Check: 0x0376e6e7}
+	// CRC64ECMA182 represents the CRC-64/ECMA-182 model.
+	CRC64ECMA182 = CRCModel{Name: "CRC-64/ECMA-182", Width: 64, Poly: 0x42f0e1eba9ea3693, Init: 0x0, XorOut: 0x0, Check: 0x6c40df5f0b497347}
+	// CRC64GOISO represents the CRC-64/GO-ISO model.
+	CRC64GOISO = CRCModel{Name: "CRC-64/GO-ISO", Width: 64, Poly: 0x000000000000001b, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffffffffffff, Check: 0xb90956c775a41001}
+	// CRC64XZ represents the CRC-64/XZ model.
+	CRC64XZ = CRCModel{Name: "CRC-64/XZ", Width: 64, Poly: 0x42f0e1eba9ea3693, Init: 0xffffffffffffffff, RefIn: true, RefOut: true, XorOut: 0xffffffffffffffff, Check: 0x995dc9bbdf1939fa}
+
+	// CRCModels represents the built-in CRC model catalogue.
+	CRCModels = []CRCModel{
+		CRC3GSM, CRC4G704, CRC5USB, CRC7MMC, CRC8SMBUS, CRC8MAXIMDOW, CRC10ATM, CRC15CAN,
+		CRC16ARC, CRC16MODBUS, CRC16CCITTFALSE, CRC16XMODEM, CRC16KERMIT, CRC16X25, CRC24OPENPGP,
+		CRC32, CRC32C, CRC32BZIP2, CRC32MPEG2, CRC64ECMA182, CRC64GOISO, CRC64XZ,
+	}
+)
+
+// CRC represents a table-driven (slicing-by-8) CRC engine.
+type CRC struct {
+	model CRCModel
+	mask  uint64
+	init  uint64
+	table [8][256]uint64
+}
+
+// NewCRC returns a new CRC engine by the given model.
+func NewCRC(model CRCModel) (*CRC, error) {
+	if model.Width < 3 || model.Width > 64 {
+		return nil, fmt.Errorf("byteman: invalid CRC width %d", model.Width)
+	}
+	mask := ^uint64(0) >> (64 - model.Width)
+	if model.Poly&^mask != 0 || model.Init&^mask != 0 || model.XorOut&^mask != 0 {
+		return nil, fmt.Errorf("byteman: CRC parameters overflow %d bits", model.Width)
+	}
+
+	c := &CRC{model: model, mask: mask}
+	if model.RefIn {
+		// The register holds the reflected CRC in the low bits.
+		poly := reflect(model.Poly, model.Width)
+		for i := range c.table[0] {
+			crc := uint64(i)
+			for j := 0; j < 8; j++ {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ poly
+				} else {
+					crc >>= 1
+				}
+			}
+			c.table[0][i] = crc
+		}
+		for i := range c.table[0] {
+			for k := 1; k < 8; k++ {
+				prev := c.table[k-1][i]
+				c.table[k][i] = prev>>8 ^ c.table[0][prev&0xff]
+			}
+		}
+		c.init = reflect(model.Init, model.Width)
+	} else {
+		// The register holds the CRC aligned to the top bit.
+		shift := 64 - model.Width
+		poly := model.Poly << shift
+		for i := range c.table[0] {
+			crc := uint64(i) << 56
+			for j := 0; j < 8; j++ {
+				if crc&(1<<63) != 0 {
+					crc = crc<<1 ^ poly
+				} else {
+					crc <<= 1
+				}
+			}
+			c.table[0][i] = crc
+		}
+		for i := range c.table[0] {
+			for k := 1; k < 8; k++ {
+				prev := c.table[k-1][i]
+				c.table[k][i] = prev<<8 ^ c.table[0][prev>>56]
+			}
+		}
+		c.init = model.Init << shift
+	}
+	return c, nil
+}
+
+// Model returns the CRC model.
+func (c *CRC) Model() CRCModel {
+	return c.model
+}
+
+// Checksum returns the CRC of the given byte slice.
+func (c *CRC) Checksum(b []byte) uint64 {
+	return c.final(c.update(c.init, b))
+}
+
+// Bytes returns the given CRC as a byte slice of (Width+7)/8 bytes by the given byte order (endianness).
+func (c *CRC) Bytes(sum uint64, bo ByteOrder) []byte {
+	n := int(c.model.Width+7) / 8
+	b := FromUint(sum, bo)
+	if bo.Type() == ByteOrderTypeBigEndian {
+		return b[8-n:]
+	}
+	return b[:n]
+}
+
+// New returns a new streaming hash by the CRC engine.
+// The Sum method appends the CRC in big-endian byte order.
+func (c *CRC) New() hash.Hash64 {
+	return &crcDigest{crc: c, reg: c.init}
+}
+
+// update returns the register value after processing the given byte slice.
+func (c *CRC) update(reg uint64, b []byte) uint64 {
+	t := &c.table
+	if c.model.RefIn {
+		for len(b) >= 8 {
+			reg ^= binary.LittleEndian.Uint64(b)
+			reg = t[7][reg&0xff] ^ t[6][reg>>8&0xff] ^ t[5][reg>>16&0xff] ^ t[4][reg>>24&0xff] ^
+				t[3][reg>>32&0xff] ^ t[2][reg>>40&0xff] ^ t[1][reg>>48&0xff] ^ t[0][reg>>56]
+			b = b[8:]
+		}
+		for _, v := range b {
+			reg = t[0][byte(reg)^v] ^ reg>>8
+		}
+		return reg
+	}
+	for len(b) >= 8 {
+		reg ^= binary.BigEndian.Uint64(b)
+		reg = t[7][reg>>56] ^ t[6][reg>>48&0xff] ^ t[5][reg>>40&0xff] ^ t[4][reg>>32&0xff] ^
+			t[3][reg>>24&0xff] ^ t[2][reg>>16&0xff] ^ t[1][reg>>8&0xff] ^ t[0][reg&0xff]
+		b = b[8:]
+	}
+	for _, v := range b {
+		reg = t[0][byte(reg>>56)^v] ^ reg<<8
+	}
+	return reg
+}
+
+// final returns the CRC by the given register value.
+func (c *CRC) final(reg uint64) uint64 {
+	if !c.model.RefIn {
+		reg >>= 64 - c.model.Width
+	}
+	if c.model.RefIn != c.model.RefOut {
+		reg = reflect(reg, c.model.Width)
+	}
+	return (reg ^ c.model.XorOut) & c.mask
+}
+
+// crcDigest represents a streaming CRC hash.
+type crcDigest struct {
+	crc *CRC
+	reg uint64
+}
+
+// Write updates the CRC by the given byte slice.
+func (d *crcDigest) Write(p []byte) (int, error) {
+	d.reg = d.crc.update(d.reg, p)
+	return len(p), nil
+}
+
+// Sum appends the current CRC in big-endian byte order to the given byte slice.
+func (d *crcDigest) Sum(in []byte) []byte {
+	return append(in, d.crc.Bytes(d.Sum64(), &BigEndian{})...)
+}
+
+// Sum64 returns the current CRC.
+func (d *crcDigest) Sum64() uint64 {
+	return d.crc.final(d.reg)
+}
+
+// Reset resets the CRC.
+func (d *crcDigest) Reset() {
+	d.reg = d.crc.init
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *crcDigest) Size() int {
+	return int(d.crc.model.Width+7) / 8
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *crcDigest) BlockSize() int {
+	return 1
+}
+
+// reflect returns the given value with its lowest width bits reversed.
+func reflect(v uint64, width uint) uint64 {
+	return bits.Reverse64(v) >> (64 - width)
+}
@@ -0,0 +1,103 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"hash/crc32"
+	"hash/crc64"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestCRCModels(t *testing.T) {
+	check := []byte("123456789")
+	for _, m := range byteman.CRCModels {
+		c, err := byteman.NewCRC(m)
+		if err != nil {
+			t.Errorf("%s: got %v, want nil", m.Name, err)
+			continue
+		}
+		if sum := c.Checksum(check); sum != m.Check {
+			t.Errorf("%s: got %#x, want %#x", m.Name, sum, m.Check)
+		}
+
+		// Streaming in uneven pieces must match the one-shot result (slicing-by-8 and byte-wise paths).
+		data := bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog"), 3)
+		h := c.New()
+		for i := 0; i < len(data); i += 7 {
+			end := i + 7
+			if end > len(data) {
+				end = len(data)
+			}
+			h.Write(data[i:end])
+		}
+		if sum := h.Sum64(); sum != c.Checksum(data) {
+			t.Errorf("%s: got %#x, want %#x", m.Name, sum, c.Checksum(data))
+		}
+		h.Reset()
+		h.Write(check)
+		if b := h.Sum(nil); !bytes.Equal(b, c.Bytes(m.Check, &byteman.BigEndian{})) || len(b) != h.Size() {
+			t.Errorf("%s: got %v, want %v", m.Name, b, c.Bytes(m.Check, &byteman.BigEndian{}))
+		}
+	}
+}
+
+func TestCRC(t *testing.T) {
+	data := bytes.Repeat([]byte("byteman"), 100)
+
+	c, _ := byteman.NewCRC(byteman.CRC32)
+	if sum, want := c.Checksum(data), uint64(crc32.ChecksumIEEE(data)); sum != want {
+		t.Errorf("got %#x, want %#x", sum, want)
+	}
+	c, _ = byteman.NewCRC(byteman.CRC32C)
+	if sum, want := c.Checksum(data), uint64(crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))); sum != want {
+		t.Errorf("got %#x, want %#x", sum, want)
+	}
+	c, _ = byteman.NewCRC(byteman.CRC64XZ)
+	if sum, want := c.Checksum(data), crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)); sum != want {
+		t.Errorf("got %#x, want %#x", sum, want)
+	}
+	c, _ = byteman.NewCRC(byteman.CRC64GOISO)
+	if sum, want := c.Checksum(data), crc64.Checksum(data, crc64.MakeTable(crc64.ISO)); sum != want {
+		t.Errorf("got %#x, want %#x", sum, want)
+	}
+
+	c, _ = byteman.NewCRC(byteman.CRC16MODBUS)
+	if b := c.Bytes(0x4b37, &byteman.LittleEndian{}); !bytes.Equal(b, []byte{0x37, 0x4b}) {
+		t.Errorf("got %v, want %v", b, []byte{0x37, 0x4b})
+	}
+	c, _ = byteman.NewCRC(byteman.CRC24OPENPGP)
+	if b := c.Bytes(0x21cf02, &byteman.BigEndian{}); !bytes.Equal(b, []byte{0x21, 0xcf, 0x02}) {
+		t.Errorf("got %v, want %v", b, []byte{0x21, 0xcf, 0x02})
+	}
+	if c.Model().Name != "CRC-24/OPENPGP" {
+		t.Errorf("got %v, want %v", c.Model().Name, "CRC-24/OPENPGP")
+	}
+
+	for _, m := range []byteman.CRCModel{{Width: 2}, {Width: 65}, {Width: 8, Poly: 0x107}, {Width: 8, Init: 0x100}} {
+		if _, err := byteman.NewCRC(m); err == nil {
+			t.Errorf("got nil, want error for %+v", m)
+		}
+	}
+}
+
+func BenchmarkCRC32(b *testing.B) {
+	c, _ := byteman.NewCRC(byteman.CRC32)
+	data := make([]byte, 4096)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		c.Checksum(data)
+	}
+}
+
+func BenchmarkCRC16CCITTFALSE(b *testing.B) {
+	c, _ := byteman.NewCRC(byteman.CRC16CCITTFALSE)
+	data := make([]byte, 4096)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		c.Checksum(data)
+	}
+}
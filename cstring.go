@@ -0,0 +1,87 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// CStringPolicy represents the policy for strings which do not fit into C string fields.
+type CStringPolicy uint8
+
+const (
+	// CStringPolicyError returns an error for strings which do not fit.
+	CStringPolicyError CStringPolicy = 0
+	// CStringPolicyTruncate truncates strings, at rune boundaries, and keeps the NUL terminator.
+	CStringPolicyTruncate CStringPolicy = 1
+	// CStringPolicyNoTerminator omits the NUL terminator if the string fills the field (strncpy semantics),
+	// and truncates the rest.
+	CStringPolicyNoTerminator CStringPolicy = 2
+)
+
+// CStringMatch represents a NUL-terminated string found in a byte slice.
+type CStringMatch struct {
+	// Offset is the offset of the first byte of the string.
+	Offset int
+	// Value is the string without the NUL terminator.
+	Value string
+}
+
+// FromCString returns a NUL-terminated byte slice by the given string, desired byte size and policy.
+// The size argument has the same semantics as the FromString size argument but it includes the NUL terminator
+// (i.e. zero means the string length plus one). It returns an error if the resolved size is zero.
+func FromCString(str string, size int, policy CStringPolicy) ([]byte, error) {
+	if i := strings.IndexByte(str, 0); i >= 0 {
+		return nil, fmt.Errorf("byteman: string contains NUL at position %d", i)
+	}
+	size = resolveSize(len(str)+1, size)
+	if size == 0 {
+		return nil, fmt.Errorf("byteman: string length %d does not fit into 0 bytes", len(str))
+	}
+	n := len(str)
+	if n >= size {
+		switch policy {
+		case CStringPolicyTruncate:
+			n = runeBoundary(str, size-1)
+		case CStringPolicyNoTerminator:
+			n = runeBoundary(str, size)
+		default:
+			return nil, fmt.Errorf("byteman: string length %d does not fit into %d bytes with NUL terminator", len(str), size)
+		}
+	}
+	b := make([]byte, size)
+	copy(b, str[:n])
+	return b, nil
+}
+
+// CString returns the string of the given byte slice up to the first NUL byte.
+// The whole byte slice is returned as a string if there is no NUL byte.
+func CString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return string(b[:i])
+	}
+	return string(b)
+}
+
+// ScanCStrings returns the NUL-terminated strings, which are at least the given minimum length,
+// in the given byte slice. The trailing bytes without a NUL terminator are ignored.
+func ScanCStrings(b []byte, min int) []CStringMatch {
+	if min < 1 {
+		min = 1
+	}
+	var matches []CStringMatch
+	for offset := 0; offset < len(b); {
+		i := bytes.IndexByte(b[offset:], 0)
+		if i < 0 {
+			break
+		}
+		if i >= min {
+			matches = append(matches, CStringMatch{Offset: offset, Value: string(b[offset : offset+i])})
+		}
+		offset += i + 1
+	}
+	return matches
+}
@@ -0,0 +1,105 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestFromCString(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		arg2 byteman.CStringPolicy
+		out  []byte
+	}{
+		{"foo", 0, byteman.CStringPolicyError, []byte{0x66, 0x6f, 0x6f, 0x00}},
+		{"foo", 6, byteman.CStringPolicyError, []byte{0x66, 0x6f, 0x6f, 0x00, 0x00, 0x00}},
+		{"foo", 4, byteman.CStringPolicyError, []byte{0x66, 0x6f, 0x6f, 0x00}},
+		{"foo", 3, byteman.CStringPolicyTruncate, []byte{0x66, 0x6f, 0x00}},
+		{"foo", -1, byteman.CStringPolicyTruncate, []byte{0x66, 0x6f, 0x00}},
+		{"foo", -3, byteman.CStringPolicyTruncate, []byte{0x00}},
+		{"foo", 3, byteman.CStringPolicyNoTerminator, []byte{0x66, 0x6f, 0x6f}},
+		{"foobar", 3, byteman.CStringPolicyNoTerminator, []byte{0x66, 0x6f, 0x6f}},
+		{"héllo", 3, byteman.CStringPolicyTruncate, []byte{0x68, 0x00, 0x00}},
+		{"héllo", 3, byteman.CStringPolicyNoTerminator, []byte{0x68, 0xc3, 0xa9}},
+		{"", 0, byteman.CStringPolicyError, []byte{0x00}},
+		{"", 2, byteman.CStringPolicyError, []byte{0x00, 0x00}},
+	}
+	for _, v := range table {
+		b, err := byteman.FromCString(v.arg0, v.arg1, v.arg2)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if _, err := byteman.FromCString("foo", 3, byteman.CStringPolicyError); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.FromCString("fo\x00o", 8, byteman.CStringPolicyTruncate); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.FromCString("abc", -10, byteman.CStringPolicyTruncate); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.FromCString("", -1, byteman.CStringPolicyTruncate); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func BenchmarkFromCString(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.FromCString("foo", 32, byteman.CStringPolicyError)
+	}
+}
+
+func TestCString(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out  string
+	}{
+		{[]byte{0x66, 0x6f, 0x6f, 0x00, 0x62, 0x61, 0x72}, "foo"},
+		{[]byte{0x66, 0x6f, 0x6f}, "foo"},
+		{[]byte{0x00, 0x66}, ""},
+		{[]byte{}, ""},
+	}
+	for _, v := range table {
+		if s := byteman.CString(v.arg0); s != v.out {
+			t.Errorf("got %v, want %v", s, v.out)
+		}
+	}
+}
+
+func TestScanCStrings(t *testing.T) {
+	b := []byte("\x00foo\x00a\x00\x00barbaz\x00qux")
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		out  []byteman.CStringMatch
+	}{
+		{b, 0, []byteman.CStringMatch{{Offset: 1, Value: "foo"}, {Offset: 5, Value: "a"}, {Offset: 8, Value: "barbaz"}}},
+		{b, 3, []byteman.CStringMatch{{Offset: 1, Value: "foo"}, {Offset: 8, Value: "barbaz"}}},
+		{b, 4, []byteman.CStringMatch{{Offset: 8, Value: "barbaz"}}},
+		{[]byte("foo"), 0, nil},
+		{nil, 0, nil},
+	}
+	for _, v := range table {
+		if m := byteman.ScanCStrings(v.arg0, v.arg1); !reflect.DeepEqual(m, v.out) {
+			t.Errorf("got %v, want %v", m, v.out)
+		}
+	}
+}
+
+func BenchmarkScanCStrings(b *testing.B) {
+	data := bytes.Repeat([]byte("foo\x00"), 100)
+	for i := 0; i < b.N; i++ {
+		byteman.ScanCStrings(data, 1)
+	}
+}
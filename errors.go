@@ -0,0 +1,93 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrChecksum is returned when a checksum verification fails.
+	ErrChecksum = errors.New("byteman: checksum mismatch")
+	// ErrInvalidPadding is returned when a padding validation fails.
+	ErrInvalidPadding = errors.New("byteman: invalid padding")
+	// ErrDumperClosed is returned when writing to a closed hex dumper.
+	ErrDumperClosed = errors.New("byteman: write to closed dumper")
+)
+
+// SyntaxError represents a syntax error in a textual input.
+type SyntaxError struct {
+	// Line is the line number (1-based). It is zero for single line inputs.
+	Line int
+	// Pos is the character position (0-based) in the input or in the line.
+	Pos int
+	// Msg is the error message.
+	Msg string
+}
+
+// Error returns the error message.
+func (e *SyntaxError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("byteman: line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("byteman: position %d: %s", e.Pos, e.Msg)
+}
+
+// TextError represents a text encoding or decoding error.
+type TextError struct {
+	// Pos is the byte position (0-based) in the input.
+	Pos int
+	// Msg is the error message.
+	Msg string
+}
+
+// Error returns the error message.
+func (e *TextError) Error() string {
+	return fmt.Sprintf("byteman: position %d: %s", e.Pos, e.Msg)
+}
+
+// LengthError represents a declared length which exceeds the available bytes or the maximum length.
+type LengthError struct {
+	// Declared is the declared length.
+	Declared uint64
+	// Available is the number of available bytes.
+	Available int
+	// Max is the maximum length (zero means no limit).
+	Max int
+}
+
+// Error returns the error message.
+func (e *LengthError) Error() string {
+	if e.Max > 0 && e.Declared > uint64(e.Max) {
+		return fmt.Sprintf("byteman: declared length %d exceeds maximum length %d", e.Declared, e.Max)
+	}
+	return fmt.Sprintf("byteman: declared length %d exceeds available %d bytes", e.Declared, e.Available)
+}
+
+// FrameError represents a malformed frame error.
+type FrameError struct {
+	// Pos is the byte position (0-based) in the encoded frame, or the bit position for bit-level framing.
+	Pos int
+	// Msg is the error message.
+	Msg string
+}
+
+// Error returns the error message.
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("byteman: frame position %d: %s", e.Pos, e.Msg)
+}
+
+// ASN1Error represents a malformed ASN.1 encoding error.
+type ASN1Error struct {
+	// Pos is the byte position (0-based) in the input.
+	Pos int
+	// Msg is the error message.
+	Msg string
+}
+
+// Error returns the error message.
+func (e *ASN1Error) Error() string {
+	return fmt.Sprintf("byteman: asn1 position %d: %s", e.Pos, e.Msg)
+}
@@ -0,0 +1,326 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	slipEnd    = 0xc0
+	slipEsc    = 0xdb
+	slipEscEnd = 0xdc
+	slipEscEsc = 0xdd
+	hdlcFlag   = 0x7e
+	hdlcEsc    = 0x7d
+	hdlcXor    = 0x20
+)
+
+// Framing represents the byte stuffing framing.
+type Framing uint8
+
+const (
+	// FramingCOBS represents the Consistent Overhead Byte Stuffing framing with 0x00 delimiters.
+	FramingCOBS Framing = 0
+	// FramingSLIP represents the SLIP (RFC 1055) framing.
+	FramingSLIP Framing = 1
+	// FramingHDLC represents the HDLC-like (RFC 1662) framing with 0x7e flags and 0x7d escapes.
+	FramingHDLC Framing = 2
+)
+
+// FCSType represents the HDLC frame check sequence type.
+type FCSType uint8
+
+const (
+	// FCSNone represents no frame check sequence.
+	FCSNone FCSType = 0
+	// FCS16 represents the 16 bit frame check sequence (CRC-16/X-25).
+	FCS16 FCSType = 1
+	// FCS32 represents the 32 bit frame check sequence (CRC-32).
+	FCS32 FCSType = 2
+)
+
+// EncodeCOBS returns the COBS encoding of the given byte slice.
+// The 0x00 frame delimiter is not appended.
+func EncodeCOBS(b []byte) []byte {
+	out := make([]byte, 1, len(b)+len(b)/254+2)
+	code, codePos := byte(1), 0
+	for i, v := range b {
+		if v != 0 {
+			out = append(out, v)
+			code++
+		}
+		if v == 0 || code == 0xff {
+			out[codePos] = code
+			code, codePos = 1, len(out)
+			if v == 0 || i < len(b)-1 {
+				out = append(out, 0)
+			}
+		}
+	}
+	if codePos < len(out) {
+		out[codePos] = code
+	}
+	return out
+}
+
+// DecodeCOBS returns the decoded byte slice by the given COBS encoded frame.
+// A trailing 0x00 frame delimiter is accepted.
+func DecodeCOBS(b []byte) ([]byte, error) {
+	if len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); {
+		code := int(b[i])
+		if code == 0 {
+			return nil, &FrameError{Pos: i, Msg: "unexpected zero byte"}
+		}
+		if i+code > len(b) {
+			return nil, &FrameError{Pos: i, Msg: "truncated block"}
+		}
+		for j := i + 1; j < i+code; j++ {
+			if b[j] == 0 {
+				return nil, &FrameError{Pos: j, Msg: "unexpected zero byte"}
+			}
+		}
+		out = append(out, b[i+1:i+code]...)
+		i += code
+		if code != 0xff && i < len(b) {
+			out = append(out, 0)
+		}
+	}
+	return out, nil
+}
+
+// EncodeSLIP returns the SLIP (RFC 1055) encoding of the given byte slice terminated by the END (0xc0) byte.
+func EncodeSLIP(b []byte) []byte {
+	out := make([]byte, 0, len(b)+len(b)/8+1)
+	for _, v := range b {
+		switch v {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, v)
+		}
+	}
+	return append(out, slipEnd)
+}
+
+// DecodeSLIP returns the decoded byte slice by the given SLIP (RFC 1055) frame.
+// Leading END bytes and a trailing END byte are accepted.
+func DecodeSLIP(b []byte) ([]byte, error) {
+	start := 0
+	for start < len(b) && b[start] == slipEnd {
+		start++
+	}
+	end := len(b)
+	if end > start && b[end-1] == slipEnd {
+		end--
+	}
+	out := make([]byte, 0, end-start)
+	for i := start; i < end; i++ {
+		switch b[i] {
+		case slipEnd:
+			return nil, &FrameError{Pos: i, Msg: "unexpected END byte"}
+		case slipEsc:
+			if i+1 >= end {
+				return nil, &FrameError{Pos: i, Msg: "incomplete escape sequence"}
+			}
+			i++
+			switch b[i] {
+			case slipEscEnd:
+				out = append(out, slipEnd)
+			case slipEscEsc:
+				out = append(out, slipEsc)
+			default:
+				return nil, &FrameError{Pos: i, Msg: fmt.Sprintf("invalid escape byte %#02x", b[i])}
+			}
+		default:
+			out = append(out, b[i])
+		}
+	}
+	return out, nil
+}
+
+// EncodeHDLC returns the HDLC-like (RFC 1662) frame of the given byte slice with opening and closing flags.
+// The frame check sequence, if any, is appended in little-endian byte order before stuffing.
+func EncodeHDLC(b []byte, fcs FCSType) []byte {
+	if fcs == FCS16 || fcs == FCS32 {
+		c := hdlcCRC(fcs)
+		b = Combine(b, c.Bytes(c.Checksum(b), &LittleEndian{}))
+	}
+	out := make([]byte, 0, len(b)+len(b)/8+2)
+	out = append(out, hdlcFlag)
+	for _, v := range b {
+		if v == hdlcFlag || v == hdlcEsc {
+			out = append(out, hdlcEsc, v^hdlcXor)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return append(out, hdlcFlag)
+}
+
+// DecodeHDLC returns the decoded byte slice by the given HDLC-like (RFC 1662) frame and frame check sequence type.
+// Opening and closing flags are optional. The frame check sequence is verified and removed.
+// It returns ErrChecksum if the verification fails.
+func DecodeHDLC(b []byte, fcs FCSType) ([]byte, error) {
+	start := 0
+	for start < len(b) && b[start] == hdlcFlag {
+		start++
+	}
+	end := len(b)
+	if end > start && b[end-1] == hdlcFlag {
+		end--
+	}
+	out := make([]byte, 0, end-start)
+	for i := start; i < end; i++ {
+		switch b[i] {
+		case hdlcFlag:
+			return nil, &FrameError{Pos: i, Msg: "unexpected flag byte"}
+		case hdlcEsc:
+			if i+1 >= end {
+				return nil, &FrameError{Pos: i, Msg: "incomplete escape sequence"}
+			}
+			i++
+			if b[i] == hdlcFlag {
+				return nil, &FrameError{Pos: i, Msg: "aborted frame"}
+			}
+			out = append(out, b[i]^hdlcXor)
+		default:
+			out = append(out, b[i])
+		}
+	}
+	return verifyFCS(out, fcs, start)
+}
+
+// verifyFCS returns the given unstuffed frame without its verified frame check sequence.
+func verifyFCS(b []byte, fcs FCSType, pos int) ([]byte, error) {
+	var n int
+	switch fcs {
+	case FCSNone:
+		return b, nil
+	case FCS16:
+		n = 2
+	case FCS32:
+		n = 4
+	default:
+		return nil, fmt.Errorf("byteman: invalid FCS type %d", fcs)
+	}
+	if len(b) < n {
+		return nil, &FrameError{Pos: pos, Msg: "frame is shorter than the frame check sequence"}
+	}
+	c := hdlcCRC(fcs)
+	data := b[:len(b)-n]
+	if !bytes.Equal(c.Bytes(c.Checksum(data), &LittleEndian{}), b[len(b)-n:]) {
+		return nil, ErrChecksum
+	}
+	return data, nil
+}
+
+var (
+	hdlcCRCOnce sync.Once
+	hdlcCRC16   *CRC
+	hdlcCRC32   *CRC
+)
+
+// hdlcCRC returns the CRC engine by the given frame check sequence type.
+func hdlcCRC(fcs FCSType) *CRC {
+	hdlcCRCOnce.Do(func() {
+		hdlcCRC16, _ = NewCRC(CRC16X25)
+		hdlcCRC32, _ = NewCRC(CRC32)
+	})
+	if fcs == FCS32 {
+		return hdlcCRC32
+	}
+	return hdlcCRC16
+}
+
+// FramerOptions represents the framer options.
+type FramerOptions struct {
+	// FCS is the frame check sequence type of HDLC frames.
+	FCS FCSType
+	// MaxSize is the maximum encoded frame size (zero means no limit).
+	// Larger frames are discarded with a *LengthError.
+	MaxSize int
+}
+
+// Framer represents a streaming framer which splits an io.Reader into decoded frames.
+type Framer struct {
+	r       *bufio.Reader
+	framing Framing
+	opts    FramerOptions
+	delim   byte
+}
+
+// NewFramer returns a new framer by the given reader, framing and options.
+func NewFramer(r io.Reader, framing Framing, opts FramerOptions) *Framer {
+	f := &Framer{r: bufio.NewReader(r), framing: framing, opts: opts}
+	switch framing {
+	case FramingSLIP:
+		f.delim = slipEnd
+	case FramingHDLC:
+		f.delim = hdlcFlag
+	}
+	return f
+}
+
+// Next returns the next decoded frame. Empty frames between delimiters are skipped.
+// Malformed frames return an error and the framer continues with the next frame.
+// It returns io.EOF at the end of the input and io.ErrUnexpectedEOF for an unterminated trailing frame.
+func (f *Framer) Next() ([]byte, error) {
+	for {
+		raw, err := f.read()
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		switch f.framing {
+		case FramingSLIP:
+			return DecodeSLIP(raw)
+		case FramingHDLC:
+			return DecodeHDLC(raw, f.opts.FCS)
+		default:
+			return DecodeCOBS(raw)
+		}
+	}
+}
+
+// read returns the next raw frame without its delimiter.
+func (f *Framer) read() ([]byte, error) {
+	var frame []byte
+	size := 0
+	for {
+		chunk, err := f.r.ReadSlice(f.delim)
+		size += len(chunk)
+		if f.opts.MaxSize <= 0 || size <= f.opts.MaxSize+1 {
+			frame = append(frame, chunk...)
+		}
+		switch err {
+		case nil:
+			if f.opts.MaxSize > 0 && size > f.opts.MaxSize+1 {
+				return nil, &LengthError{Declared: uint64(size - 1), Available: size - 1, Max: f.opts.MaxSize}
+			}
+			return frame[:len(frame)-1], nil
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if size > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, io.EOF
+		default:
+			return nil, err
+		}
+	}
+}
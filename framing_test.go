@@ -0,0 +1,219 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+// seq returns a byte slice with the values from first to last.
+func seq(first, last int) []byte {
+	b := make([]byte, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		b = append(b, byte(i))
+	}
+	return b
+}
+
+func TestCOBS(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out  []byte
+	}{
+		{[]byte{}, []byte{0x01}},
+		{[]byte{0x00}, []byte{0x01, 0x01}},
+		{[]byte{0x00, 0x00}, []byte{0x01, 0x01, 0x01}},
+		{[]byte{0x00, 0x11, 0x00}, []byte{0x01, 0x02, 0x11, 0x01}},
+		{[]byte{0x11, 0x22, 0x00, 0x33}, []byte{0x03, 0x11, 0x22, 0x02, 0x33}},
+		{[]byte{0x11, 0x22, 0x33, 0x44}, []byte{0x05, 0x11, 0x22, 0x33, 0x44}},
+		{[]byte{0x11, 0x00, 0x00, 0x00}, []byte{0x02, 0x11, 0x01, 0x01, 0x01}},
+		{seq(1, 254), byteman.Combine([]byte{0xff}, seq(1, 254))},
+		{seq(0, 254), byteman.Combine([]byte{0x01, 0xff}, seq(1, 254))},
+		{seq(1, 255), byteman.Combine([]byte{0xff}, seq(1, 254), []byte{0x02, 0xff})},
+		{byteman.Combine(seq(2, 255), []byte{0x00}), byteman.Combine([]byte{0xff}, seq(2, 255), []byte{0x01, 0x01})},
+		{byteman.Combine(seq(3, 255), []byte{0x00, 0x01}), byteman.Combine([]byte{0xfe}, seq(3, 255), []byte{0x02, 0x01})},
+	}
+	for _, v := range table {
+		if b := byteman.EncodeCOBS(v.arg0); !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+		if b, err := byteman.DecodeCOBS(append(v.out, 0x00)); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.arg0) {
+			t.Errorf("got %v, want %v", b, v.arg0)
+		}
+	}
+
+	errs := []struct {
+		arg0 []byte
+		pos  int
+	}{
+		{[]byte{0x03, 0x11}, 0},
+		{[]byte{0x03, 0x11, 0x00, 0x01}, 2},
+		{[]byte{0x02, 0x11, 0x00, 0x00}, 2},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeCOBS(v.arg0)
+		if e, ok := err.(*byteman.FrameError); !ok {
+			t.Errorf("got %v, want FrameError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func BenchmarkEncodeCOBS(b *testing.B) {
+	data := seq(0, 255)
+	for i := 0; i < b.N; i++ {
+		byteman.EncodeCOBS(data)
+	}
+}
+
+func TestSLIP(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out  []byte
+	}{
+		{[]byte{}, []byte{0xc0}},
+		{[]byte{0x01, 0x02}, []byte{0x01, 0x02, 0xc0}},
+		{[]byte{0xc0, 0xdb, 0x01}, []byte{0xdb, 0xdc, 0xdb, 0xdd, 0x01, 0xc0}},
+	}
+	for _, v := range table {
+		if b := byteman.EncodeSLIP(v.arg0); !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+		if b, err := byteman.DecodeSLIP(append([]byte{0xc0}, v.out...)); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.arg0) {
+			t.Errorf("got %v, want %v", b, v.arg0)
+		}
+	}
+
+	errs := []struct {
+		arg0 []byte
+		pos  int
+	}{
+		{[]byte{0x01, 0xdb, 0x02, 0xc0}, 2},
+		{[]byte{0x01, 0xdb, 0xc0}, 1},
+		{[]byte{0x01, 0xc0, 0x02, 0xc0}, 1},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeSLIP(v.arg0)
+		if e, ok := err.(*byteman.FrameError); !ok {
+			t.Errorf("got %v, want FrameError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func TestHDLC(t *testing.T) {
+	if b := byteman.EncodeHDLC([]byte{0x01, 0x7e, 0x7d}, byteman.FCSNone); !bytes.Equal(b, []byte{0x7e, 0x01, 0x7d, 0x5e, 0x7d, 0x5d, 0x7e}) {
+		t.Errorf("got %v, want %v", b, []byte{0x7e, 0x01, 0x7d, 0x5e, 0x7d, 0x5d, 0x7e})
+	}
+
+	// The CRC-16/X-25 of "123456789" is 0x906e, transmitted least significant byte first.
+	want := byteman.Combine([]byte{0x7e}, []byte("123456789"), []byte{0x6e, 0x90, 0x7e})
+	if b := byteman.EncodeHDLC([]byte("123456789"), byteman.FCS16); !bytes.Equal(b, want) {
+		t.Errorf("got %v, want %v", b, want)
+	}
+
+	for _, fcs := range []byte{byte(byteman.FCSNone), byte(byteman.FCS16), byte(byteman.FCS32)} {
+		for _, data := range [][]byte{{}, []byte("foo"), seq(0, 255)} {
+			frame := byteman.EncodeHDLC(data, byteman.FCSType(fcs))
+			if b, err := byteman.DecodeHDLC(frame, byteman.FCSType(fcs)); err != nil {
+				t.Errorf("got %v, want nil", err)
+			} else if !bytes.Equal(b, data) {
+				t.Errorf("got %v, want %v", b, data)
+			}
+		}
+	}
+
+	frame := byteman.EncodeHDLC([]byte("foo"), byteman.FCS32)
+	frame[1] ^= 0x01
+	if _, err := byteman.DecodeHDLC(frame, byteman.FCS32); err != byteman.ErrChecksum {
+		t.Errorf("got %v, want %v", err, byteman.ErrChecksum)
+	}
+	if _, err := byteman.DecodeHDLC([]byte{0x7e, 0x01, 0x7e}, byteman.FCS16); err == nil {
+		t.Error("got nil, want error")
+	}
+
+	errs := []struct {
+		arg0 []byte
+		pos  int
+	}{
+		{[]byte{0x7e, 0x01, 0x7d, 0x7e}, 2},
+		{[]byte{0x7e, 0x01, 0x7d, 0x7e, 0x02, 0x7e}, 3},
+		{[]byte{0x01, 0x7e, 0x02}, 1},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeHDLC(v.arg0, byteman.FCSNone)
+		if e, ok := err.(*byteman.FrameError); !ok {
+			t.Errorf("got %v, want FrameError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func BenchmarkEncodeHDLC(b *testing.B) {
+	data := seq(0, 255)
+	for i := 0; i < b.N; i++ {
+		byteman.EncodeHDLC(data, byteman.FCS16)
+	}
+}
+
+func TestFramer(t *testing.T) {
+	frames := [][]byte{[]byte("foo"), {0x00, 0xc0, 0x7e, 0x7d, 0xdb}, bytes.Repeat([]byte{0x55}, 5000)}
+
+	var cobs, slip, hdlc []byte
+	for _, f := range frames {
+		cobs = append(append(cobs, byteman.EncodeCOBS(f)...), 0x00)
+		slip = append(append(slip, 0xc0), byteman.EncodeSLIP(f)...)
+		hdlc = append(hdlc, byteman.EncodeHDLC(f, byteman.FCS16)...)
+	}
+	table := []struct {
+		arg0 []byte
+		arg1 byteman.Framing
+	}{
+		{cobs, byteman.FramingCOBS},
+		{slip, byteman.FramingSLIP},
+		{hdlc, byteman.FramingHDLC},
+	}
+	for _, v := range table {
+		f := byteman.NewFramer(bytes.NewReader(v.arg0), v.arg1, byteman.FramerOptions{FCS: byteman.FCS16})
+		for _, want := range frames {
+			if b, err := f.Next(); err != nil {
+				t.Errorf("got %v, want nil", err)
+			} else if !bytes.Equal(b, want) {
+				t.Errorf("got %v, want %v", b, want)
+			}
+		}
+		if _, err := f.Next(); err != io.EOF {
+			t.Errorf("got %v, want %v", err, io.EOF)
+		}
+	}
+
+	// Malformed and oversized frames are reported and skipped.
+	input := byteman.Combine([]byte{0x01, 0xdb, 0x02, 0xc0}, bytes.Repeat([]byte{0x01}, 20), []byte{0xc0}, []byte("ok"), []byte{0xc0, 0x03})
+	f := byteman.NewFramer(bytes.NewReader(input), byteman.FramingSLIP, byteman.FramerOptions{MaxSize: 16})
+	if _, err := f.Next(); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := f.Next(); err == nil {
+		t.Error("got nil, want error")
+	} else if _, ok := err.(*byteman.LengthError); !ok {
+		t.Errorf("got %v, want LengthError", err)
+	}
+	if b, err := f.Next(); err != nil || !bytes.Equal(b, []byte("ok")) {
+		t.Errorf("got %v, %v, want %v", b, err, []byte("ok"))
+	}
+	if _, err := f.Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
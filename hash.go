@@ -0,0 +1,548 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math/bits"
+)
+
+const (
+	prime32x1 = 0x9e3779b1
+	prime32x2 = 0x85ebca77
+	prime32x3 = 0xc2b2ae3d
+	prime64x1 = 0x9e3779b185ebca87
+	prime64x2 = 0xc2b2ae3d27d4eb4f
+	prime64x3 = 0x165667b19e3779f9
+	prime64x4 = 0x85ebca77c2b2ae63
+	prime64x5 = 0x27d4eb2f165667c5
+)
+
+// FNV32 returns the 32 bit FNV-1 hash of the given byte slice.
+func FNV32(b []byte) uint32 {
+	h := fnv.New32()
+	h.Write(b)
+	return h.Sum32()
+}
+
+// FNV32a returns the 32 bit FNV-1a hash of the given byte slice.
+func FNV32a(b []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(b)
+	return h.Sum32()
+}
+
+// FNV64 returns the 64 bit FNV-1 hash of the given byte slice.
+func FNV64(b []byte) uint64 {
+	h := fnv.New64()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// FNV64a returns the 64 bit FNV-1a hash of the given byte slice.
+func FNV64a(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// FNV128 returns the 128 bit FNV-1 hash of the given byte slice in big-endian byte order.
+func FNV128(b []byte) []byte {
+	h := fnv.New128()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// FNV128a returns the 128 bit FNV-1a hash of the given byte slice in big-endian byte order.
+func FNV128a(b []byte) []byte {
+	h := fnv.New128a()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// NewFNV32 returns a new streaming 32 bit FNV-1 hash.
+func NewFNV32() hash.Hash32 {
+	return fnv.New32()
+}
+
+// NewFNV32a returns a new streaming 32 bit FNV-1a hash.
+func NewFNV32a() hash.Hash32 {
+	return fnv.New32a()
+}
+
+// NewFNV64 returns a new streaming 64 bit FNV-1 hash.
+func NewFNV64() hash.Hash64 {
+	return fnv.New64()
+}
+
+// NewFNV64a returns a new streaming 64 bit FNV-1a hash.
+func NewFNV64a() hash.Hash64 {
+	return fnv.New64a()
+}
+
+// NewFNV128 returns a new streaming 128 bit FNV-1 hash.
+func NewFNV128() hash.Hash {
+	return fnv.New128()
+}
+
+// NewFNV128a returns a new streaming 128 bit FNV-1a hash.
+func NewFNV128a() hash.Hash {
+	return fnv.New128a()
+}
+
+// Murmur32 returns the MurmurHash3 (x86_32) hash of the given byte slice by the given seed.
+func Murmur32(b []byte, seed uint32) uint32 {
+	d := murmur32Digest{seed: seed, h: seed}
+	d.Write(b)
+	return d.Sum32()
+}
+
+// NewMurmur32 returns a new streaming MurmurHash3 (x86_32) hash by the given seed.
+func NewMurmur32(seed uint32) hash.Hash32 {
+	return &murmur32Digest{seed: seed, h: seed}
+}
+
+// Murmur128 returns the MurmurHash3 (x64_128) hash of the given byte slice by the given seed as two 64 bit halves.
+func Murmur128(b []byte, seed uint32) (uint64, uint64) {
+	d := murmur128Digest{seed: seed, h1: uint64(seed), h2: uint64(seed)}
+	d.Write(b)
+	return d.Sum128()
+}
+
+// NewMurmur128 returns a new streaming MurmurHash3 (x64_128) hash by the given seed.
+// The Sum method appends the two 64 bit halves in big-endian byte order.
+func NewMurmur128(seed uint32) hash.Hash {
+	return &murmur128Digest{seed: seed, h1: uint64(seed), h2: uint64(seed)}
+}
+
+// XXHash64 returns the xxHash64 hash of the given byte slice by the given seed.
+func XXHash64(b []byte, seed uint64) uint64 {
+	var d xxh64Digest
+	d.reset(seed)
+	d.Write(b)
+	return d.Sum64()
+}
+
+// NewXXHash64 returns a new streaming xxHash64 hash by the given seed.
+func NewXXHash64(seed uint64) hash.Hash64 {
+	d := &xxh64Digest{}
+	d.reset(seed)
+	return d
+}
+
+// SipHash24 returns the SipHash-2-4 hash of the given byte slice by the given 128 bit key halves.
+// A 16 byte key can be converted by Uint64(key[:8], &LittleEndian{}) and Uint64(key[8:], &LittleEndian{}).
+func SipHash24(b []byte, k0, k1 uint64) uint64 {
+	var d sipDigest
+	d.reset(k0, k1)
+	d.Write(b)
+	return d.Sum64()
+}
+
+// NewSipHash24 returns a new streaming SipHash-2-4 hash by the given 128 bit key halves.
+func NewSipHash24(k0, k1 uint64) hash.Hash64 {
+	d := &sipDigest{}
+	d.reset(k0, k1)
+	return d
+}
+
+// murmur32Digest represents a streaming MurmurHash3 (x86_32) hash.
+type murmur32Digest struct {
+	seed uint32
+	h    uint32
+	n    int
+	buf  [4]byte
+	nbuf int
+}
+
+// Write updates the hash by the given byte slice.
+func (d *murmur32Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.n += n
+	if d.nbuf > 0 {
+		c := copy(d.buf[d.nbuf:], p)
+		d.nbuf, p = d.nbuf+c, p[c:]
+		if d.nbuf < 4 {
+			return n, nil
+		}
+		d.h = murmur32Block(d.h, binary.LittleEndian.Uint32(d.buf[:]))
+		d.nbuf = 0
+	}
+	h := d.h
+	for len(p) >= 4 {
+		h = murmur32Block(h, binary.LittleEndian.Uint32(p))
+		p = p[4:]
+	}
+	d.h = h
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// Sum appends the current hash in big-endian byte order to the given byte slice.
+func (d *murmur32Digest) Sum(in []byte) []byte {
+	return append(in, FromUint(d.Sum32(), &BigEndian{})...)
+}
+
+// Sum32 returns the current hash.
+func (d *murmur32Digest) Sum32() uint32 {
+	h := d.h
+	if d.nbuf > 0 {
+		var k uint32
+		for i := d.nbuf - 1; i >= 0; i-- {
+			k = k<<8 | uint32(d.buf[i])
+		}
+		h ^= bits.RotateLeft32(k*0xcc9e2d51, 15) * 0x1b873593
+	}
+	h ^= uint32(d.n)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// Reset resets the hash.
+func (d *murmur32Digest) Reset() {
+	*d = murmur32Digest{seed: d.seed, h: d.seed}
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *murmur32Digest) Size() int {
+	return 4
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *murmur32Digest) BlockSize() int {
+	return 4
+}
+
+// murmur32Block returns the MurmurHash3 (x86_32) state updated by the given block.
+func murmur32Block(h, k uint32) uint32 {
+	h ^= bits.RotateLeft32(k*0xcc9e2d51, 15) * 0x1b873593
+	return bits.RotateLeft32(h, 13)*5 + 0xe6546b64
+}
+
+// murmur128Digest represents a streaming MurmurHash3 (x64_128) hash.
+type murmur128Digest struct {
+	seed   uint32
+	h1, h2 uint64
+	n      int
+	buf    [16]byte
+	nbuf   int
+}
+
+const (
+	murmur128C1 = 0x87c37b91114253d5
+	murmur128C2 = 0x4cf5ad432745937f
+)
+
+// Write updates the hash by the given byte slice.
+func (d *murmur128Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.n += n
+	if d.nbuf > 0 {
+		c := copy(d.buf[d.nbuf:], p)
+		d.nbuf, p = d.nbuf+c, p[c:]
+		if d.nbuf < 16 {
+			return n, nil
+		}
+		d.block(d.buf[:])
+		d.nbuf = 0
+	}
+	for len(p) >= 16 {
+		d.block(p)
+		p = p[16:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// block updates the hash by the given 16 byte block.
+func (d *murmur128Digest) block(p []byte) {
+	k1, k2 := binary.LittleEndian.Uint64(p), binary.LittleEndian.Uint64(p[8:])
+	h1, h2 := d.h1, d.h2
+	h1 ^= bits.RotateLeft64(k1*murmur128C1, 31) * murmur128C2
+	h1 = (bits.RotateLeft64(h1, 27)+h2)*5 + 0x52dce729
+	h2 ^= bits.RotateLeft64(k2*murmur128C2, 33) * murmur128C1
+	h2 = (bits.RotateLeft64(h2, 31)+h1)*5 + 0x38495ab5
+	d.h1, d.h2 = h1, h2
+}
+
+// Sum appends the current hash halves in big-endian byte order to the given byte slice.
+func (d *murmur128Digest) Sum(in []byte) []byte {
+	h1, h2 := d.Sum128()
+	return append(append(in, FromUint(h1, &BigEndian{})...), FromUint(h2, &BigEndian{})...)
+}
+
+// Sum128 returns the current hash halves.
+func (d *murmur128Digest) Sum128() (uint64, uint64) {
+	h1, h2 := d.h1, d.h2
+	if d.nbuf > 0 {
+		var k1, k2 uint64
+		for i := d.nbuf - 1; i >= 8; i-- {
+			k2 = k2<<8 | uint64(d.buf[i])
+		}
+		for i := min8(d.nbuf, 8) - 1; i >= 0; i-- {
+			k1 = k1<<8 | uint64(d.buf[i])
+		}
+		if d.nbuf > 8 {
+			h2 ^= bits.RotateLeft64(k2*murmur128C2, 33) * murmur128C1
+		}
+		h1 ^= bits.RotateLeft64(k1*murmur128C1, 31) * murmur128C2
+	}
+	h1 ^= uint64(d.n)
+	h2 ^= uint64(d.n)
+	h1 += h2
+	h2 += h1
+	h1, h2 = murmurMix64(h1), murmurMix64(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+// Reset resets the hash.
+func (d *murmur128Digest) Reset() {
+	*d = murmur128Digest{seed: d.seed, h1: uint64(d.seed), h2: uint64(d.seed)}
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *murmur128Digest) Size() int {
+	return 16
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *murmur128Digest) BlockSize() int {
+	return 16
+}
+
+// murmurMix64 returns the MurmurHash3 64 bit finalization mix of the given value.
+func murmurMix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
+
+// min8 returns the smaller of the given values.
+func min8(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// xxh64Digest represents a streaming xxHash64 hash.
+type xxh64Digest struct {
+	seed uint64
+	v    [4]uint64
+	n    uint64
+	buf  [32]byte
+	nbuf int
+}
+
+// reset resets the hash by the given seed.
+func (d *xxh64Digest) reset(seed uint64) {
+	*d = xxh64Digest{seed: seed}
+	d.v = [4]uint64{seed + prime64x1 + prime64x2, seed + prime64x2, seed, seed - prime64x1}
+}
+
+// Write updates the hash by the given byte slice.
+func (d *xxh64Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.n += uint64(n)
+	if d.nbuf > 0 {
+		c := copy(d.buf[d.nbuf:], p)
+		d.nbuf, p = d.nbuf+c, p[c:]
+		if d.nbuf < 32 {
+			return n, nil
+		}
+		d.stripe(d.buf[:])
+		d.nbuf = 0
+	}
+	for len(p) >= 32 {
+		d.stripe(p)
+		p = p[32:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// stripe updates the accumulators by the given 32 byte stripe.
+func (d *xxh64Digest) stripe(p []byte) {
+	d.v[0] = xxh64Round(d.v[0], binary.LittleEndian.Uint64(p))
+	d.v[1] = xxh64Round(d.v[1], binary.LittleEndian.Uint64(p[8:]))
+	d.v[2] = xxh64Round(d.v[2], binary.LittleEndian.Uint64(p[16:]))
+	d.v[3] = xxh64Round(d.v[3], binary.LittleEndian.Uint64(p[24:]))
+}
+
+// Sum appends the current hash in big-endian byte order to the given byte slice.
+func (d *xxh64Digest) Sum(in []byte) []byte {
+	return append(in, FromUint(d.Sum64(), &BigEndian{})...)
+}
+
+// Sum64 returns the current hash.
+func (d *xxh64Digest) Sum64() uint64 {
+	var h uint64
+	if d.n >= 32 {
+		v := d.v
+		h = bits.RotateLeft64(v[0], 1) + bits.RotateLeft64(v[1], 7) + bits.RotateLeft64(v[2], 12) + bits.RotateLeft64(v[3], 18)
+		for _, x := range v {
+			h ^= xxh64Round(0, x)
+			h = h*prime64x1 + prime64x4
+		}
+	} else {
+		h = d.seed + prime64x5
+	}
+	h += d.n
+
+	p := d.buf[:d.nbuf]
+	for ; len(p) >= 8; p = p[8:] {
+		h ^= xxh64Round(0, binary.LittleEndian.Uint64(p))
+		h = bits.RotateLeft64(h, 27)*prime64x1 + prime64x4
+	}
+	if len(p) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(p)) * prime64x1
+		h = bits.RotateLeft64(h, 23)*prime64x2 + prime64x3
+		p = p[4:]
+	}
+	for _, c := range p {
+		h ^= uint64(c) * prime64x5
+		h = bits.RotateLeft64(h, 11) * prime64x1
+	}
+	return xxh64Avalanche(h)
+}
+
+// Reset resets the hash.
+func (d *xxh64Digest) Reset() {
+	d.reset(d.seed)
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *xxh64Digest) Size() int {
+	return 8
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *xxh64Digest) BlockSize() int {
+	return 32
+}
+
+// xxh64Round returns the xxHash64 accumulator updated by the given lane.
+func xxh64Round(acc, lane uint64) uint64 {
+	return bits.RotateLeft64(acc+lane*prime64x2, 31) * prime64x1
+}
+
+// xxh64Avalanche returns the xxHash64 final mix of the given value.
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= prime64x2
+	h ^= h >> 29
+	h *= prime64x3
+	h ^= h >> 32
+	return h
+}
+
+// sipDigest represents a streaming SipHash-2-4 hash.
+type sipDigest struct {
+	k0, k1         uint64
+	v0, v1, v2, v3 uint64
+	n              int
+	buf            [8]byte
+	nbuf           int
+}
+
+// reset resets the hash by the given key halves.
+func (d *sipDigest) reset(k0, k1 uint64) {
+	*d = sipDigest{k0: k0, k1: k1}
+	d.v0 = k0 ^ 0x736f6d6570736575
+	d.v1 = k1 ^ 0x646f72616e646f6d
+	d.v2 = k0 ^ 0x6c7967656e657261
+	d.v3 = k1 ^ 0x7465646279746573
+}
+
+// Write updates the hash by the given byte slice.
+func (d *sipDigest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.n += n
+	if d.nbuf > 0 {
+		c := copy(d.buf[d.nbuf:], p)
+		d.nbuf, p = d.nbuf+c, p[c:]
+		if d.nbuf < 8 {
+			return n, nil
+		}
+		d.v0, d.v1, d.v2, d.v3 = sipCompress(d.v0, d.v1, d.v2, d.v3, binary.LittleEndian.Uint64(d.buf[:]))
+		d.nbuf = 0
+	}
+	v0, v1, v2, v3 := d.v0, d.v1, d.v2, d.v3
+	for len(p) >= 8 {
+		v0, v1, v2, v3 = sipCompress(v0, v1, v2, v3, binary.LittleEndian.Uint64(p))
+		p = p[8:]
+	}
+	d.v0, d.v1, d.v2, d.v3 = v0, v1, v2, v3
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// Sum appends the current hash in big-endian byte order to the given byte slice.
+func (d *sipDigest) Sum(in []byte) []byte {
+	return append(in, FromUint(d.Sum64(), &BigEndian{})...)
+}
+
+// Sum64 returns the current hash.
+func (d *sipDigest) Sum64() uint64 {
+	m := uint64(d.n) << 56
+	for i := d.nbuf - 1; i >= 0; i-- {
+		m |= uint64(d.buf[i]) << (8 * uint(i))
+	}
+	v0, v1, v2, v3 := sipCompress(d.v0, d.v1, d.v2, d.v3, m)
+	v2 ^= 0xff
+	for i := 0; i < 4; i++ {
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	}
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+// Reset resets the hash.
+func (d *sipDigest) Reset() {
+	d.reset(d.k0, d.k1)
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *sipDigest) Size() int {
+	return 8
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *sipDigest) BlockSize() int {
+	return 8
+}
+
+// sipCompress returns the SipHash-2-4 state updated by the given message word.
+func sipCompress(v0, v1, v2, v3, m uint64) (uint64, uint64, uint64, uint64) {
+	v3 ^= m
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= m
+	return v0, v1, v2, v3
+}
+
+// sipRound returns the SipHash state after a SipRound.
+func sipRound(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13) ^ v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16) ^ v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21) ^ v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17) ^ v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
@@ -0,0 +1,242 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"hash"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+// writeChunks writes the given byte slice to the given hash in uneven chunks.
+func writeChunks(h hash.Hash, b []byte) {
+	for i, c := 0, 1; i < len(b); i, c = i+c, c*2+1 {
+		end := i + c
+		if end > len(b) {
+			end = len(b)
+		}
+		h.Write(b[i:end])
+	}
+}
+
+func TestFNV(t *testing.T) {
+	b := []byte("hello, world")
+	if h := byteman.FNV32(b); h != 0x55e6879d {
+		t.Errorf("got %#x, want %#x", h, 0x55e6879d)
+	}
+	if h := byteman.FNV32a([]byte("a")); h != 0xe40c292c {
+		t.Errorf("got %#x, want %#x", h, 0xe40c292c)
+	}
+	if h := byteman.FNV64a([]byte("a")); h != 0xaf63dc4c8601ec8c {
+		t.Errorf("got %#x, want %#x", h, uint64(0xaf63dc4c8601ec8c))
+	}
+	if h := byteman.FNV64([]byte("a")); h != 0xaf63bd4c8601b7be {
+		t.Errorf("got %#x, want %#x", h, uint64(0xaf63bd4c8601b7be))
+	}
+	want := []byte{0x6c, 0x62, 0x27, 0x2e, 0x07, 0xbb, 0x01, 0x42, 0x62, 0xb8, 0x21, 0x75, 0x62, 0x95, 0xc5, 0x8d}
+	if h := byteman.FNV128a([]byte{}); !bytes.Equal(h, want) {
+		t.Errorf("got %x, want %x", h, want)
+	}
+	h := byteman.NewFNV128a()
+	if s := h.Sum(nil); !bytes.Equal(s, want) {
+		t.Errorf("got %x, want %x", s, want)
+	}
+	if h := byteman.FNV128(b); len(h) != 16 {
+		t.Errorf("got %v, want %v", len(h), 16)
+	}
+}
+
+func TestMurmur32(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 uint32
+		out  uint32
+	}{
+		{"", 0, 0},
+		{"", 1, 0x514e28b7},
+		{"a", 0, 0x3c2569b2},
+		{"abc", 0, 0xb3dd93fa},
+		{"hello, world", 1, 0x6f5cb2e9},
+		{"The quick brown fox jumps over the lazy dog", 0, 0x2e4ff723},
+	}
+	for _, v := range table {
+		if h := byteman.Murmur32([]byte(v.arg0), v.arg1); h != v.out {
+			t.Errorf("got %#x, want %#x", h, v.out)
+		}
+		h := byteman.NewMurmur32(v.arg1)
+		writeChunks(h, []byte(v.arg0))
+		if s := h.Sum32(); s != v.out {
+			t.Errorf("got %#x, want %#x", s, v.out)
+		}
+	}
+}
+
+func BenchmarkMurmur32(b *testing.B) {
+	data := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		byteman.Murmur32(data, 0)
+	}
+}
+
+func TestMurmur128(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 uint32
+		out0 uint64
+		out1 uint64
+	}{
+		{"", 1, 0x4610abe56eff5cb5, 0x51622daa78f83583},
+		{"a", 1, 0x47eae1073748cf70, 0x6be0518ad2ed3728},
+		{"hello, world", 1, 0x8b95f808840725c6, 0x1597ed5422bd493b},
+		{"The quick brown fox jumps over the lazy dog", 0, 0xe34bbc7bbc071b6c, 0x7a433ca9c49a9347},
+	}
+	for _, v := range table {
+		if h1, h2 := byteman.Murmur128([]byte(v.arg0), v.arg1); h1 != v.out0 || h2 != v.out1 {
+			t.Errorf("got %#x %#x, want %#x %#x", h1, h2, v.out0, v.out1)
+		}
+		h := byteman.NewMurmur128(v.arg1)
+		writeChunks(h, []byte(v.arg0))
+		want := byteman.Combine(byteman.FromUint(v.out0, &byteman.BigEndian{}), byteman.FromUint(v.out1, &byteman.BigEndian{}))
+		if s := h.Sum(nil); !bytes.Equal(s, want) {
+			t.Errorf("got %x, want %x", s, want)
+		}
+	}
+}
+
+func TestXXHash64(t *testing.T) {
+	long := bytes.Repeat([]byte("0123456789abcdef"), 100)[:1500]
+	table := []struct {
+		arg0 []byte
+		arg1 uint64
+		out  uint64
+	}{
+		{[]byte(""), 0, 0xef46db3751d8e999},
+		{[]byte("a"), 0, 0xd24ec4f1a98c6e5b},
+		{[]byte("abc"), 0, 0x44bc2cf5ad770999},
+		{[]byte("hello, world"), 0, 0xb33a384e6d1b1242},
+		{[]byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789$"), 0, 0x1032d841e824f998},
+		{[]byte("The quick brown fox jumps over the lazy dog"), 0, 0x0b242d361fda71bc},
+		{long, 0, 0xf128a43f35037c28},
+	}
+	for _, v := range table {
+		if h := byteman.XXHash64(v.arg0, v.arg1); h != v.out {
+			t.Errorf("got %#x, want %#x", h, v.out)
+		}
+		h := byteman.NewXXHash64(v.arg1)
+		writeChunks(h, v.arg0)
+		if s := h.Sum64(); s != v.out {
+			t.Errorf("got %#x, want %#x", s, v.out)
+		}
+		h.Reset()
+		h.Write(v.arg0)
+		if s := h.Sum(nil); !bytes.Equal(s, byteman.FromUint(v.out, &byteman.BigEndian{})) {
+			t.Errorf("got %x, want %x", s, byteman.FromUint(v.out, &byteman.BigEndian{}))
+		}
+	}
+}
+
+func BenchmarkXXHash64(b *testing.B) {
+	data := make([]byte, 1024)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		byteman.XXHash64(data, 0)
+	}
+}
+
+func TestXXH3(t *testing.T) {
+	long := bytes.Repeat([]byte("0123456789abcdef"), 100)[:1500]
+	table := []struct {
+		arg0 []byte
+		arg1 uint64
+		out  uint64
+	}{
+		{[]byte(""), 0, 0x2d06800538d394c2},
+		{[]byte(""), 1, 0x4dc5b0cc826f6703},
+		{[]byte("a"), 0, 0xe6c632b61e964e1f},
+		{[]byte("abc"), 0, 0x78af5f94892f3950},
+		{[]byte("abc"), 1, 0x6b4467b443c76228},
+		{[]byte("hello, world"), 0, 0x302cd5fba73d006c},
+		{[]byte("hello, world"), 1, 0x12009fe09bc44ae6},
+		{[]byte("The quick brown fox jumps over the lazy dog"), 0, 0xce7d19a5418fb365},
+		{[]byte("The quick brown fox jumps over the lazy dog"), 1, 0x1e098210b55fad4a},
+		{long, 0, 0xfeb12c50cb1267d4},
+		{long, 0x9e3779b97f4a7c15, 0x1f62a370a52494ca},
+	}
+	for _, v := range table {
+		if h := byteman.XXH3(v.arg0, v.arg1); h != v.out {
+			t.Errorf("got %#x, want %#x", h, v.out)
+		}
+		h := byteman.NewXXH3(v.arg1)
+		writeChunks(h, v.arg0)
+		if s := h.Sum64(); s != v.out {
+			t.Errorf("got %#x, want %#x", s, v.out)
+		}
+	}
+
+	// Streaming must match the one-shot hash around the buffer, stripe and block boundaries.
+	data := bytes.Repeat([]byte("byteman"), 700)
+	for _, n := range []int{129, 240, 241, 255, 256, 257, 320, 1024, 1025, 2048, 4096, len(data)} {
+		for _, c := range []int{1, 63, 64, 256, 300} {
+			h := byteman.NewXXH3(7)
+			for i := 0; i < n; i += c {
+				end := i + c
+				if end > n {
+					end = n
+				}
+				h.Write(data[i:end])
+			}
+			if s, want := h.Sum64(), byteman.XXH3(data[:n], 7); s != want {
+				t.Errorf("got %#x, want %#x (%d bytes by %d)", s, want, n, c)
+			}
+		}
+	}
+}
+
+func BenchmarkXXH3(b *testing.B) {
+	data := make([]byte, 1024)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		byteman.XXH3(data, 0)
+	}
+}
+
+func TestSipHash24(t *testing.T) {
+	// Reference vectors with the key 00 01 02 ... 0f and the message 00 01 02 ... (n-1).
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	k0, k1 := byteman.Uint64(key[:8], &byteman.LittleEndian{}), byteman.Uint64(key[8:], &byteman.LittleEndian{})
+	msg := make([]byte, 64)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	table := []struct {
+		arg0 int
+		out  uint64
+	}{
+		{0, 0x726fdb47dd0e0e31},
+		{1, 0x74f839c593dc67fd},
+		{7, 0xab0200f58b01d137},
+		{8, 0x93f5f5799a932462},
+		{15, 0xa129ca6149be45e5},
+		{63, 0x958a324ceb064572},
+	}
+	for _, v := range table {
+		if h := byteman.SipHash24(msg[:v.arg0], k0, k1); h != v.out {
+			t.Errorf("got %#x, want %#x", h, v.out)
+		}
+		h := byteman.NewSipHash24(k0, k1)
+		writeChunks(h, msg[:v.arg0])
+		if s := h.Sum64(); s != v.out {
+			t.Errorf("got %#x, want %#x", s, v.out)
+		}
+	}
+}
+
+func BenchmarkSipHash24(b *testing.B) {
+	data := make([]byte, 64)
+	for i := 0; i < b.N; i++ {
+		byteman.SipHash24(data, 1, 2)
+	}
+}
@@ -0,0 +1,543 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DumpStyle represents the hex dump style.
+type DumpStyle uint8
+
+const (
+	// DumpStyleHexdump represents the `hexdump -C` style.
+	DumpStyleHexdump DumpStyle = 0
+	// DumpStyleXxd represents the `xxd` style.
+	DumpStyleXxd DumpStyle = 1
+	// DumpStylePlain represents the plain grouped-hex style (no offsets and no ASCII gutter).
+	DumpStylePlain DumpStyle = 2
+)
+
+const (
+	hexLower = "0123456789abcdef"
+	hexUpper = "0123456789ABCDEF"
+)
+
+// DumpOptions represents the hex dump options.
+// The zero value produces `hexdump -C` compatible output without squeezing.
+type DumpOptions struct {
+	// Style is the dump style.
+	Style DumpStyle
+	// Base is the offset base (8, 10 or 16). Default is 16.
+	Base int
+	// Width is the number of bytes per line. Default is 16.
+	Width int
+	// Group is the number of bytes per group. Default is 8 for hexdump, 2 for xxd and 1 for plain style.
+	Group int
+	// Uppercase enables uppercase hex letters.
+	Uppercase bool
+	// NoASCII hides the ASCII gutter.
+	NoASCII bool
+	// Squeeze collapses repeated lines into a single `*` line. It is ignored for the plain style
+	// since the repeated lines can not be recovered without offsets.
+	Squeeze bool
+}
+
+// Dump returns the hex dump of the given byte slice by the given options.
+func Dump(b []byte, opts DumpOptions) string {
+	var sb strings.Builder
+	d := NewDumper(&sb, opts)
+	d.Write(b)
+	d.Close()
+	return sb.String()
+}
+
+// Dumper represents a streaming hex dumper.
+// The output is written to the underlying writer line by line and Close must be called to flush the last line.
+type Dumper struct {
+	w        io.Writer
+	opts     DumpOptions
+	digits   string
+	line     []byte
+	prev     []byte
+	offset   int
+	squeezed int
+	closed   bool
+}
+
+// NewDumper returns a new hex dumper which writes to the given writer.
+func NewDumper(w io.Writer, opts DumpOptions) *Dumper {
+	if opts.Base != 8 && opts.Base != 10 {
+		opts.Base = 16
+	}
+	if opts.Width <= 0 {
+		opts.Width = 16
+	}
+	if opts.Style == DumpStylePlain {
+		opts.Squeeze = false
+	}
+	if opts.Group <= 0 {
+		switch opts.Style {
+		case DumpStyleXxd:
+			opts.Group = 2
+		case DumpStylePlain:
+			opts.Group = 1
+		default:
+			opts.Group = 8
+		}
+	}
+	d := &Dumper{
+		w:      w,
+		opts:   opts,
+		digits: hexLower,
+		line:   make([]byte, 0, opts.Width),
+	}
+	if opts.Uppercase {
+		d.digits = hexUpper
+	}
+	return d
+}
+
+// Write writes the hex dump of the given byte slice. It returns ErrDumperClosed after Close.
+func (d *Dumper) Write(p []byte) (n int, err error) {
+	if d.closed {
+		return 0, ErrDumperClosed
+	}
+	for len(p) > 0 {
+		c := copy(d.line[len(d.line):d.opts.Width], p)
+		d.line = d.line[:len(d.line)+c]
+		p = p[c:]
+		n += c
+		if len(d.line) == d.opts.Width {
+			if err = d.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close flushes the remaining bytes and writes the final offset (hexdump style) or,
+// if the input ends with squeezed lines, the last line (xxd style).
+// It does not close the underlying writer.
+func (d *Dumper) Close() error {
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	if len(d.line) > 0 {
+		if err := d.flush(); err != nil {
+			return err
+		}
+	}
+	if d.squeezed > 0 {
+		if err := d.closeSqueeze(); err != nil {
+			return err
+		}
+	}
+	if d.opts.Style == DumpStyleHexdump && d.offset > 0 {
+		out := d.appendOffset(nil, d.offset)
+		out = append(out, '\n')
+		_, err := d.w.Write(out)
+		return err
+	}
+	return nil
+}
+
+// flush writes the pending line.
+func (d *Dumper) flush() error {
+	offset := d.offset
+	d.offset += len(d.line)
+	defer func() { d.line = d.line[:0] }()
+
+	if d.opts.Squeeze && len(d.line) == d.opts.Width && bytes.Equal(d.line, d.prev) {
+		d.squeezed++
+		return nil
+	}
+	if d.squeezed > 0 {
+		d.squeezed = 0
+		if _, err := d.w.Write([]byte("*\n")); err != nil {
+			return err
+		}
+	}
+	d.prev = append(d.prev[:0], d.line...)
+
+	_, err := d.w.Write(d.format(offset))
+	return err
+}
+
+// closeSqueeze writes the squeezed lines at the end of the input.
+// Like `xxd -a`, the xxd style writes the last line so the dump length is preserved.
+func (d *Dumper) closeSqueeze() error {
+	if d.opts.Style != DumpStyleXxd || d.squeezed > 1 {
+		if _, err := d.w.Write([]byte("*\n")); err != nil {
+			return err
+		}
+	}
+	if d.opts.Style == DumpStyleXxd {
+		d.line = append(d.line[:0], d.prev...)
+		_, err := d.w.Write(d.format(d.offset - len(d.line)))
+		d.line = d.line[:0]
+		return err
+	}
+	return nil
+}
+
+// format returns the formatted pending line.
+func (d *Dumper) format(offset int) []byte {
+	width, group := d.opts.Width, d.opts.Group
+	out := make([]byte, 0, 16+width*4+width/group)
+
+	switch d.opts.Style {
+	case DumpStyleXxd:
+		out = d.appendOffset(out, offset)
+		out = append(out, ':', ' ')
+		for i := 0; i < width; i++ {
+			if i < len(d.line) {
+				out = append(out, d.digits[d.line[i]>>4], d.digits[d.line[i]&0x0f])
+			} else {
+				out = append(out, ' ', ' ')
+			}
+			if (i+1)%group == 0 || i == width-1 {
+				out = append(out, ' ')
+			}
+		}
+		if d.opts.NoASCII {
+			out = bytes.TrimRight(out, " ")
+		} else {
+			out = append(out, ' ')
+			out = d.appendASCII(out)
+		}
+	case DumpStylePlain:
+		for i, c := range d.line {
+			if i > 0 && i%group == 0 {
+				out = append(out, ' ')
+			}
+			out = append(out, d.digits[c>>4], d.digits[c&0x0f])
+		}
+	default:
+		out = d.appendOffset(out, offset)
+		out = append(out, ' ', ' ')
+		for i := 0; i < width; i++ {
+			if i > 0 && i%group == 0 {
+				out = append(out, ' ')
+			}
+			if i < len(d.line) {
+				out = append(out, d.digits[d.line[i]>>4], d.digits[d.line[i]&0x0f], ' ')
+			} else {
+				out = append(out, ' ', ' ', ' ')
+			}
+		}
+		if d.opts.NoASCII {
+			out = bytes.TrimRight(out, " ")
+		} else {
+			out = append(out, ' ', '|')
+			out = d.appendASCII(out)
+			out = append(out, '|')
+		}
+	}
+	return append(out, '\n')
+}
+
+// appendOffset appends the given offset as an 8 digit number.
+func (d *Dumper) appendOffset(out []byte, offset int) []byte {
+	var buf [24]byte
+	i := len(buf)
+	for v := uint64(offset); v > 0 || i > len(buf)-8; v /= uint64(d.opts.Base) {
+		i--
+		buf[i] = d.digits[v%uint64(d.opts.Base)]
+	}
+	return append(out, buf[i:]...)
+}
+
+// appendASCII appends the printable representation of the pending line.
+func (d *Dumper) appendASCII(out []byte) []byte {
+	for _, c := range d.line {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// ParseDump returns a byte slice by the given hex dump.
+// It accepts `xxd`, `hexdump -C`, Wireshark "Copy as Hex Dump" and `od -t x1` outputs.
+// Offsets and ASCII gutters are stripped, `*` lines are expanded and the offset continuity is validated.
+// The offsets are hexadecimal except 7 digit offsets (`od` default) which are octal. Decimal and octal
+// 8 digit offsets (Dump with Base 10 or 8) are detected by the offset continuity; hexadecimal is preferred
+// if the offsets are valid in more than one base, which might happen for squeezed dumps.
+// The first offset is not required to be zero.
+// Dumps without offsets (e.g. the plain style) are not accepted, see DecodeHex for them.
+func ParseDump(str string) ([]byte, error) {
+	var lines []dumpLine
+	for i, line := range strings.Split(str, "\n") {
+		ln := i + 1
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if trimmed == "*" {
+			lines = append(lines, dumpLine{ln: ln, squeeze: true})
+			continue
+		}
+
+		// Offset
+		field := trimmed
+		if idx := strings.IndexAny(field, " \t"); idx >= 0 {
+			field = field[:idx]
+		}
+		rest := trimmed[len(field):]
+		colon := strings.HasSuffix(field, ":")
+		field = strings.TrimSuffix(field, ":")
+		if len(field) < minDumpOffsetDigits || !isHex(field) {
+			return nil, &SyntaxError{Line: ln, Msg: fmt.Sprintf("invalid offset %q", field)}
+		}
+
+		// Data
+		cands, err := parseDumpLine(rest)
+		if err != nil {
+			return nil, &SyntaxError{Line: ln, Msg: err.Error()}
+		}
+		lines = append(lines, dumpLine{ln: ln, offset: field, colon: colon, cands: cands})
+	}
+
+	bases := []int{16, 10, 8}
+	for _, l := range lines {
+		if !l.squeeze {
+			if len(l.offset) == 7 && !l.colon {
+				bases = []int{8}
+			}
+			break
+		}
+	}
+	var firstErr error
+	for _, base := range bases {
+		b, err := assembleDump(lines, base)
+		if err == nil {
+			return b, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// minDumpOffsetDigits represents the minimum number of offset digits (Wireshark) of the accepted hex dumps.
+const minDumpOffsetDigits = 4
+
+// dumpLine represents a parsed hex dump line.
+type dumpLine struct {
+	ln      int
+	squeeze bool
+	offset  string
+	colon   bool
+	cands   [][]byte
+}
+
+// assembleDump returns the bytes of the given parsed hex dump lines by the given offset base.
+func assembleDump(lines []dumpLine, base int) ([]byte, error) {
+	offsets := make([]int, len(lines))
+	for i, l := range lines {
+		if l.squeeze {
+			continue
+		}
+		offset, err := strconv.ParseUint(l.offset, base, 63)
+		if err != nil {
+			return nil, &SyntaxError{Line: l.ln, Msg: fmt.Sprintf("invalid offset %q", l.offset)}
+		}
+		offsets[i] = int(offset)
+	}
+
+	var (
+		b        []byte
+		prev     []byte
+		next     int
+		squeezed int
+		first    = true
+	)
+	for i, l := range lines {
+		if l.squeeze {
+			if prev == nil {
+				return nil, &SyntaxError{Line: l.ln, Msg: "unexpected squeeze line"}
+			}
+			squeezed = l.ln
+			continue
+		}
+		offset := offsets[i]
+
+		// Continuity
+		if first {
+			next, first = offset, false
+		}
+		if squeezed > 0 {
+			if offset <= next || (offset-next)%len(prev) != 0 {
+				return nil, &SyntaxError{Line: l.ln, Msg: fmt.Sprintf("invalid offset %#x after squeeze line", offset)}
+			}
+			for next < offset {
+				b = append(b, prev...)
+				next += len(prev)
+			}
+			squeezed = 0
+		} else if offset != next {
+			return nil, &SyntaxError{Line: l.ln, Msg: fmt.Sprintf("invalid offset %#x, want %#x", offset, next)}
+		}
+
+		data := chooseDumpLine(lines, offsets, i)
+		b = append(b, data...)
+		next += len(data)
+		if len(data) > 0 {
+			prev = data
+		}
+	}
+	if squeezed > 0 {
+		return nil, &SyntaxError{Line: squeezed, Msg: "missing offset after squeeze line"}
+	}
+	if b == nil {
+		b = []byte{}
+	}
+	return b, nil
+}
+
+// chooseDumpLine returns the bytes of the line at the given index which are consistent with the offset of the
+// next line. It returns the first candidate if there is no next line or none of them is consistent.
+func chooseDumpLine(lines []dumpLine, offsets []int, i int) []byte {
+	cands := lines[i].cands
+	squeeze := false
+	for j := i + 1; j < len(lines); j++ {
+		if lines[j].squeeze {
+			squeeze = true
+			continue
+		}
+		end := offsets[i]
+		for _, data := range cands {
+			switch n := offsets[j] - end - len(data); {
+			case !squeeze && n == 0:
+				return data
+			case squeeze && len(data) > 0 && n > 0 && n%len(data) == 0:
+				return data
+			}
+		}
+		break
+	}
+	return cands[0]
+}
+
+// parseDumpLine returns the possible bytes of the given hex dump line (without offset), the ones followed
+// by the longest ASCII gutter first. A line without an ASCII column might end with hex tokens that look
+// like a gutter, so the offset of the next line decides (see assembleDump).
+func parseDumpLine(line string) ([][]byte, error) {
+	if strings.TrimSpace(line) == "" {
+		return [][]byte{nil}, nil
+	}
+
+	// Collect the leading hex tokens and their end positions.
+	var (
+		tokens []string
+		starts []int
+		ends   []int
+		cands  [][]byte
+	)
+	for i := 0; i < len(line); {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+			i++
+		}
+		j := i
+		for j < len(line) && line[j] != ' ' && line[j] != '\t' {
+			j++
+		}
+		token := line[i:j]
+		if token == "" || len(token)%2 != 0 || !isHex(token) {
+			break
+		}
+		tokens = append(tokens, token)
+		starts, ends = append(starts, i), append(ends, j)
+		i = j
+	}
+
+	// Find the token sequences followed by a matching ASCII gutter. The gutter must be
+	// delimited or separated by a wider gap than the gaps between the hex tokens.
+	for n := len(tokens); n > 0; n-- {
+		tail := line[ends[n-1]:]
+		gutter := strings.TrimLeft(tail, " \t")
+		if gutter == "" {
+			continue
+		}
+		if !isDelimitedGutter(gutter) {
+			gap := len(tail) - len(gutter)
+			wide := gap >= 2
+			for k := 1; k < n && wide; k++ {
+				wide = gap > starts[k]-ends[k-1]
+			}
+			if !wide {
+				continue
+			}
+		}
+		if data := decodeTokens(tokens[:n]); matchGutter(data, gutter) {
+			cands = append(cands, data)
+		}
+	}
+	if len(tokens) > 0 && strings.TrimLeft(line[ends[len(tokens)-1]:], " \t") == "" {
+		cands = append(cands, decodeTokens(tokens))
+	}
+	if len(cands) == 0 {
+		return nil, fmt.Errorf("invalid hex data %q", strings.TrimSpace(line))
+	}
+	return cands, nil
+}
+
+// decodeTokens returns the decoded bytes of the given hex tokens.
+func decodeTokens(tokens []string) []byte {
+	var b []byte
+	for _, token := range tokens {
+		d, _ := hex.DecodeString(token)
+		b = append(b, d...)
+	}
+	return b
+}
+
+// matchGutter returns whether the given ASCII gutter, with or without `|…|` or `>…<` delimiters,
+// represents the given bytes.
+func matchGutter(b []byte, gutter string) bool {
+	want := make([]byte, len(b))
+	for i, c := range b {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		want[i] = c
+	}
+	// Leading and trailing spaces might be lost while copying dumps.
+	ws := strings.TrimSpace(string(want))
+	if ws == strings.TrimSpace(gutter) {
+		return true
+	}
+	return isDelimitedGutter(gutter) && ws == strings.TrimSpace(gutter[1:len(gutter)-1])
+}
+
+// isDelimitedGutter returns whether the given ASCII gutter is delimited by `|…|` (hexdump) or `>…<` (od).
+func isDelimitedGutter(gutter string) bool {
+	return len(gutter) >= 2 && ((gutter[0] == '|' && gutter[len(gutter)-1] == '|') || (gutter[0] == '>' && gutter[len(gutter)-1] == '<'))
+}
+
+// isHex returns whether the given string consists of hex digits.
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHexDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHexDigit returns whether the given character is a hex digit.
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,180 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestDump(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 byteman.DumpOptions
+		out  string
+	}{
+		{[]byte{}, byteman.DumpOptions{}, ""},
+		{[]byte("hello world\n"), byteman.DumpOptions{},
+			"00000000  68 65 6c 6c 6f 20 77 6f  72 6c 64 0a              |hello world.|\n" +
+				"0000000c\n"},
+		{[]byte("hello world\n"), byteman.DumpOptions{Style: byteman.DumpStyleXxd},
+			"00000000: 6865 6c6c 6f20 776f 726c 640a            hello world.\n"},
+		{[]byte("hello world\n"), byteman.DumpOptions{Style: byteman.DumpStyleXxd, Uppercase: true, NoASCII: true},
+			"00000000: 6865 6C6C 6F20 776F 726C 640A\n"},
+		{[]byte("hello world\n"), byteman.DumpOptions{Style: byteman.DumpStylePlain, Width: 8, Group: 4},
+			"68656c6c 6f20776f\n726c640a\n"},
+		{[]byte("hello world\n"), byteman.DumpOptions{Base: 10, Width: 4, Group: 2, NoASCII: true},
+			"00000000  68 65  6c 6c\n00000004  6f 20  77 6f\n00000008  72 6c  64 0a\n00000012\n"},
+		{make([]byte, 64), byteman.DumpOptions{Squeeze: true},
+			"00000000  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|\n" +
+				"*\n" +
+				"00000040\n"},
+		{append(make([]byte, 48), 'A'), byteman.DumpOptions{Style: byteman.DumpStyleXxd, Base: 8, Squeeze: true},
+			"00000000: 0000 0000 0000 0000 0000 0000 0000 0000  ................\n" +
+				"*\n" +
+				"00000060: 41                                       A\n"},
+		{make([]byte, 64), byteman.DumpOptions{Style: byteman.DumpStyleXxd, Squeeze: true},
+			"00000000: 0000 0000 0000 0000 0000 0000 0000 0000  ................\n" +
+				"*\n" +
+				"00000030: 0000 0000 0000 0000 0000 0000 0000 0000  ................\n"},
+		{make([]byte, 4), byteman.DumpOptions{Style: byteman.DumpStyleXxd, Width: 2, Squeeze: true},
+			"00000000: 0000  ..\n" +
+				"00000002: 0000  ..\n"},
+		{make([]byte, 6), byteman.DumpOptions{Style: byteman.DumpStylePlain, Width: 2, Squeeze: true},
+			"00 00\n00 00\n00 00\n"},
+	}
+	for _, v := range table {
+		s := byteman.Dump(v.arg0, v.arg1)
+		if s != v.out {
+			t.Errorf("got %q, want %q", s, v.out)
+		}
+	}
+
+	// The default style must match encoding/hex plus the final offset line hexdump -C prints.
+	b := []byte("The quick brown fox jumps over the lazy dog\x00\x01\x02\xff")
+	if s, want := byteman.Dump(b, byteman.DumpOptions{}), hex.Dump(b)+"0000002f\n"; s != want {
+		t.Errorf("got %q, want %q", s, want)
+	}
+}
+
+func BenchmarkDump(b *testing.B) {
+	data := bytes.Repeat([]byte("foo"), 100)
+	for i := 0; i < b.N; i++ {
+		byteman.Dump(data, byteman.DumpOptions{})
+	}
+}
+
+func TestDumper(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog")
+	for _, style := range []byteman.DumpStyle{byteman.DumpStyleHexdump, byteman.DumpStyleXxd, byteman.DumpStylePlain} {
+		opts := byteman.DumpOptions{Style: style}
+		var sb strings.Builder
+		d := byteman.NewDumper(&sb, opts)
+		for i := 0; i < len(data); i += 5 {
+			end := i + 5
+			if end > len(data) {
+				end = len(data)
+			}
+			if _, err := d.Write(data[i:end]); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+		}
+		if err := d.Close(); err != nil {
+			t.Errorf("got %v, want nil", err)
+		}
+		if s, want := sb.String(), byteman.Dump(data, opts); s != want {
+			t.Errorf("got %q, want %q", s, want)
+		}
+		if _, err := d.Write(data); err != byteman.ErrDumperClosed {
+			t.Errorf("got %v, want %v", err, byteman.ErrDumperClosed)
+		}
+	}
+}
+
+func TestParseDump(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog\x00\x01\x02\xff")
+	zeros := append(make([]byte, 64), 'A')
+	table := []struct {
+		arg0 string
+		out  []byte
+	}{
+		{"", []byte{}},
+		{"0000000\n", []byte{}},
+		{byteman.Dump(data, byteman.DumpOptions{}), data},
+		{byteman.Dump(data, byteman.DumpOptions{Style: byteman.DumpStyleXxd}), data},
+		{byteman.Dump(data, byteman.DumpOptions{Style: byteman.DumpStyleXxd, Group: 4, Uppercase: true}), data},
+		{byteman.Dump(data, byteman.DumpOptions{NoASCII: true}), data},
+		{byteman.Dump(zeros, byteman.DumpOptions{Squeeze: true}), zeros},
+		{byteman.Dump(zeros, byteman.DumpOptions{Style: byteman.DumpStyleXxd, Squeeze: true}), zeros},
+		{byteman.Dump(make([]byte, 64), byteman.DumpOptions{Style: byteman.DumpStyleXxd, Squeeze: true}), make([]byte, 64)},
+		// xxd with an ASCII gutter which looks like hex.
+		{"00000000: 6162 6364 6566                           abcdef\n", []byte("abcdef")},
+		// Wireshark "Copy as Hex Dump".
+		{"0000   ff ff ff ff ff ff 00 11 22 33 44 55 08 06 00 01   ........\"3DU....\n" +
+			"0010   08 00 06 04 00 01                                 ......\n",
+			[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x06, 0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01}},
+		// od -t x1 with octal offsets.
+		{"0000000 68 65 6c 6c 6f 20 77 6f 72 6c 64 0a 68 65 6c 6c\n0000020 6f\n0000021\n", []byte("hello world\nhello")},
+		// od -A x -t x1z
+		{"000000 68 65 6c 6c 6f  >hello<\n000005\n", []byte("hello")},
+		// Pasted with indentation and CRLF line endings.
+		{"  00000000  68 69 0a                                          |hi.|\r\n  00000003\r\n", []byte("hi\n")},
+		// xxd with ASCII gutters which look delimited.
+		{"00000000: 7c20 636f 6c31 207c 2063 6f6c 3220 7c0a  | col1 | col2 |.\n" +
+			"00000010: 3e68 746d 6c20 636f 6e74 656e 7421 213c  >html content!!<\n",
+			[]byte("| col1 | col2 |\n>html content!!<")},
+		{"00000000: 7c63 6f6c 3120 636f 6c32 2063 6f6c 337c  |col1 col2 col3|\n", []byte("|col1 col2 col3|")},
+		{byteman.Dump(data, byteman.DumpOptions{Style: byteman.DumpStyleXxd, Width: 17, Group: 3}), data},
+		// Hex tokens which look like an ASCII gutter.
+		{byteman.Dump([]byte("333"), byteman.DumpOptions{Style: byteman.DumpStyleXxd, NoASCII: true}), []byte("333")},
+		{byteman.Dump(bytes.Repeat([]byte("3"), 9), byteman.DumpOptions{Width: 3, NoASCII: true}), bytes.Repeat([]byte("3"), 9)},
+		{byteman.Dump([]byte("00\x0000"), byteman.DumpOptions{Width: 3, Group: 2, NoASCII: true}), []byte("00\x0000")},
+		// Decimal and octal offsets.
+		{byteman.Dump(data, byteman.DumpOptions{Base: 10}), data},
+		{byteman.Dump(data, byteman.DumpOptions{Style: byteman.DumpStyleXxd, Base: 8}), data},
+		{byteman.Dump(zeros, byteman.DumpOptions{Base: 10, Squeeze: true}), zeros},
+	}
+	for _, v := range table {
+		b, err := byteman.ParseDump(v.arg0)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	errs := []struct {
+		arg0 string
+		line int
+	}{
+		{"*\n00000010\n", 1},
+		{"00000000  68 69\nxyz  68 69\n", 2},
+		{"00000000  68 69\n00000004  68 69\n", 2},
+		{"00000000  68 6\n", 1},
+		{"00000000  68 69  |ho|\n", 1},
+		{"00000000  00 00\n*\n00000003\n", 3},
+		{"00000000  00 00\n*\n", 2},
+		// Dumps without offsets.
+		{"61 62 63 61 62\n", 1},
+	}
+	for _, v := range errs {
+		_, err := byteman.ParseDump(v.arg0)
+		if e, ok := err.(*byteman.SyntaxError); !ok {
+			t.Errorf("got %v, want SyntaxError", err)
+		} else if e.Line != v.line {
+			t.Errorf("got %v, want %v", e.Line, v.line)
+		}
+	}
+}
+
+func BenchmarkParseDump(b *testing.B) {
+	dump := byteman.Dump(bytes.Repeat([]byte("foo"), 100), byteman.DumpOptions{})
+	for i := 0; i < b.N; i++ {
+		byteman.ParseDump(dump)
+	}
+}
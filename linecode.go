@@ -0,0 +1,191 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+// ManchesterConvention represents the Manchester encoding convention.
+type ManchesterConvention uint8
+
+const (
+	// ManchesterIEEE represents the IEEE 802.3 convention (0 is high-to-low, 1 is low-to-high).
+	ManchesterIEEE ManchesterConvention = 0
+	// ManchesterThomas represents the G.E. Thomas convention (0 is low-to-high, 1 is high-to-low).
+	ManchesterThomas ManchesterConvention = 1
+)
+
+// NRZIMode represents the NRZI transition rule.
+type NRZIMode uint8
+
+const (
+	// NRZIToggleOnZero represents the HDLC and USB rule (0 is a transition, 1 is no transition).
+	NRZIToggleOnZero NRZIMode = 0
+	// NRZIToggleOnOne represents the NRZ-M rule (1 is a transition, 0 is no transition).
+	NRZIToggleOnOne NRZIMode = 1
+)
+
+// StuffBits returns the HDLC zero-bit inserted bit stream and its number of bits by the given
+// byte slice, number of bits (negative means all bits) and bit order.
+// A 0 bit is inserted after every five consecutive 1 bits.
+func StuffBits(b []byte, n int, order BitOrder) ([]byte, int) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	ones := 0
+	for bit, err := r.ReadBit(); err == nil; bit, err = r.ReadBit() {
+		w.WriteBit(bit)
+		if bit == 0 {
+			ones = 0
+			continue
+		}
+		if ones++; ones == 5 {
+			w.WriteBit(0)
+			ones = 0
+		}
+	}
+	return w.Bytes(), w.Len()
+}
+
+// UnstuffBits returns the HDLC zero-bit removed bit stream and its number of bits by the given
+// byte slice, number of bits (negative means all bits) and bit order.
+// Six or more consecutive 1 bits (a flag or an abort sequence) return a *FrameError with the bit position.
+func UnstuffBits(b []byte, n int, order BitOrder) ([]byte, int, error) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	ones := 0
+	for i := 0; ; i++ {
+		bit, err := r.ReadBit()
+		if err != nil {
+			break
+		}
+		if ones == 5 {
+			if bit != 0 {
+				return nil, 0, &FrameError{Pos: i, Msg: "six consecutive one bits"}
+			}
+			ones = 0
+			continue
+		}
+		w.WriteBit(bit)
+		if bit == 0 {
+			ones = 0
+		} else {
+			ones++
+		}
+	}
+	return w.Bytes(), w.Len(), nil
+}
+
+// EncodeManchester returns the Manchester encoded bit stream and its number of bits by the given
+// byte slice, number of bits (negative means all bits), bit order and convention.
+// Each bit is encoded as two half-bit symbols.
+func EncodeManchester(b []byte, n int, order BitOrder, conv ManchesterConvention) ([]byte, int) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	for bit, err := r.ReadBit(); err == nil; bit, err = r.ReadBit() {
+		first := bit ^ 1
+		if conv == ManchesterThomas {
+			first = bit
+		}
+		w.WriteBit(first)
+		w.WriteBit(first ^ 1)
+	}
+	return w.Bytes(), w.Len()
+}
+
+// DecodeManchester returns the decoded bit stream and its number of bits by the given Manchester encoded
+// byte slice, number of bits (negative means all bits), bit order and convention.
+// A symbol pair without a mid-bit transition or a trailing half symbol returns a *FrameError with the bit position.
+func DecodeManchester(b []byte, n int, order BitOrder, conv ManchesterConvention) ([]byte, int, error) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	for i := 0; r.Len() > 0; i += 2 {
+		if r.Len() < 2 {
+			return nil, 0, &FrameError{Pos: i, Msg: "incomplete symbol"}
+		}
+		first, _ := r.ReadBit()
+		second, _ := r.ReadBit()
+		if first == second {
+			return nil, 0, &FrameError{Pos: i, Msg: "missing mid-bit transition"}
+		}
+		if conv == ManchesterThomas {
+			w.WriteBit(first)
+		} else {
+			w.WriteBit(second)
+		}
+	}
+	return w.Bytes(), w.Len(), nil
+}
+
+// EncodeDifferentialManchester returns the differential Manchester encoded bit stream and its number of bits
+// by the given byte slice, number of bits (negative means all bits), bit order and initial line level.
+// Every bit has a mid-bit transition, and a 0 bit also has a transition at the start of the bit period.
+func EncodeDifferentialManchester(b []byte, n int, order BitOrder, level uint8) ([]byte, int) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	level &= 1
+	for bit, err := r.ReadBit(); err == nil; bit, err = r.ReadBit() {
+		if bit == 0 {
+			level ^= 1
+		}
+		w.WriteBit(level)
+		level ^= 1
+		w.WriteBit(level)
+	}
+	return w.Bytes(), w.Len()
+}
+
+// DecodeDifferentialManchester returns the decoded bit stream and its number of bits by the given differential
+// Manchester encoded byte slice, number of bits (negative means all bits), bit order and initial line level.
+// A symbol pair without a mid-bit transition or a trailing half symbol returns a *FrameError with the bit position.
+func DecodeDifferentialManchester(b []byte, n int, order BitOrder, level uint8) ([]byte, int, error) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	level &= 1
+	for i := 0; r.Len() > 0; i += 2 {
+		if r.Len() < 2 {
+			return nil, 0, &FrameError{Pos: i, Msg: "incomplete symbol"}
+		}
+		first, _ := r.ReadBit()
+		second, _ := r.ReadBit()
+		if first == second {
+			return nil, 0, &FrameError{Pos: i, Msg: "missing mid-bit transition"}
+		}
+		if first == level {
+			w.WriteBit(1)
+		} else {
+			w.WriteBit(0)
+		}
+		level = second
+	}
+	return w.Bytes(), w.Len(), nil
+}
+
+// EncodeNRZI returns the NRZI encoded bit stream (line levels) and its number of bits by the given
+// byte slice, number of bits (negative means all bits), bit order, transition rule and initial line level.
+func EncodeNRZI(b []byte, n int, order BitOrder, mode NRZIMode, level uint8) ([]byte, int) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	toggle := uint8(0)
+	if mode == NRZIToggleOnOne {
+		toggle = 1
+	}
+	level &= 1
+	for bit, err := r.ReadBit(); err == nil; bit, err = r.ReadBit() {
+		if bit == toggle {
+			level ^= 1
+		}
+		w.WriteBit(level)
+	}
+	return w.Bytes(), w.Len()
+}
+
+// DecodeNRZI returns the decoded bit stream and its number of bits by the given NRZI encoded (line levels)
+// byte slice, number of bits (negative means all bits), bit order, transition rule and initial line level.
+func DecodeNRZI(b []byte, n int, order BitOrder, mode NRZIMode, level uint8) ([]byte, int) {
+	r, w := NewBitReader(b, n, order), NewBitWriter(order)
+	toggle := uint8(0)
+	if mode == NRZIToggleOnOne {
+		toggle = 1
+	}
+	level &= 1
+	for bit, err := r.ReadBit(); err == nil; bit, err = r.ReadBit() {
+		if bit != level {
+			w.WriteBit(toggle)
+		} else {
+			w.WriteBit(toggle ^ 1)
+		}
+		level = bit
+	}
+	return w.Bytes(), w.Len()
+}
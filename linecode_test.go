@@ -0,0 +1,154 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestStuffBits(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.BitOrder
+		out0 []byte
+		out1 int
+	}{
+		{[]byte{0x7e}, -1, byteman.BitOrderMSB, []byte{0x7d, 0x00}, 9},
+		{[]byte{0xff}, 8, byteman.BitOrderMSB, []byte{0xfb, 0x80}, 9},
+		{[]byte{0xf8}, 5, byteman.BitOrderMSB, []byte{0xf8}, 6},
+		{[]byte{0x3f}, 6, byteman.BitOrderLSB, []byte{0x5f}, 7},
+		{[]byte{0x55}, 8, byteman.BitOrderMSB, []byte{0x55}, 8},
+		{[]byte{}, 0, byteman.BitOrderMSB, nil, 0},
+	}
+	for _, v := range table {
+		b, n := byteman.StuffBits(v.arg0, v.arg1, v.arg2)
+		if !bytes.Equal(b, v.out0) || n != v.out1 {
+			t.Errorf("got %#v %v, want %#v %v", b, n, v.out0, v.out1)
+		}
+		u, un, err := byteman.UnstuffBits(b, n, v.arg2)
+		if want := byteman.NewBitReader(v.arg0, v.arg1, v.arg2).Len(); err != nil || un != want {
+			t.Errorf("got %v %v, want %v", un, err, want)
+		} else if r := byteman.NewBitReader(v.arg0, v.arg1, v.arg2); un > 0 {
+			want, _ := r.ReadBits(un)
+			if got, _ := byteman.NewBitReader(u, un, v.arg2).ReadBits(un); got != want {
+				t.Errorf("got %#x, want %#x", got, want)
+			}
+		}
+	}
+
+	if _, _, err := byteman.UnstuffBits([]byte{0x7e}, 8, byteman.BitOrderMSB); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.FrameError); !ok || e.Pos != 6 {
+		t.Errorf("got %v, want bit position 6", err)
+	}
+}
+
+func BenchmarkStuffBits(b *testing.B) {
+	data := bytes.Repeat([]byte{0xff}, 64)
+	for i := 0; i < b.N; i++ {
+		byteman.StuffBits(data, -1, byteman.BitOrderLSB)
+	}
+}
+
+func TestManchester(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.BitOrder
+		arg3 byteman.ManchesterConvention
+		out0 []byte
+		out1 int
+	}{
+		{[]byte{0xa0}, 3, byteman.BitOrderMSB, byteman.ManchesterIEEE, []byte{0x64}, 6},
+		{[]byte{0xa0}, 3, byteman.BitOrderMSB, byteman.ManchesterThomas, []byte{0x98}, 6},
+		{[]byte{0xf0}, -1, byteman.BitOrderMSB, byteman.ManchesterIEEE, []byte{0x55, 0xaa}, 16},
+		{[]byte{0x01}, 1, byteman.BitOrderLSB, byteman.ManchesterIEEE, []byte{0x02}, 2},
+	}
+	for _, v := range table {
+		b, n := byteman.EncodeManchester(v.arg0, v.arg1, v.arg2, v.arg3)
+		if !bytes.Equal(b, v.out0) || n != v.out1 {
+			t.Errorf("got %#v %v, want %#v %v", b, n, v.out0, v.out1)
+		}
+		d, dn, err := byteman.DecodeManchester(b, n, v.arg2, v.arg3)
+		if err != nil || dn != n/2 {
+			t.Errorf("got %v %v, want %v", dn, err, n/2)
+		} else if got, want := byteman.Resize(d, 1)[0]>>uint(8-dn), v.arg0[0]>>uint(8-dn); v.arg2 == byteman.BitOrderMSB && got != want {
+			t.Errorf("got %#x, want %#x", got, want)
+		}
+	}
+
+	errs := []struct {
+		arg0 []byte
+		arg1 int
+		pos  int
+	}{
+		{[]byte{0x6c}, 8, 4},
+		{[]byte{0x60}, 3, 2},
+	}
+	for _, v := range errs {
+		_, _, err := byteman.DecodeManchester(v.arg0, v.arg1, byteman.BitOrderMSB, byteman.ManchesterIEEE)
+		if e, ok := err.(*byteman.FrameError); !ok {
+			t.Errorf("got %v, want FrameError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func TestDifferentialManchester(t *testing.T) {
+	b, n := byteman.EncodeDifferentialManchester([]byte{0x60}, 4, byteman.BitOrderMSB, 0)
+	if !bytes.Equal(b, []byte{0x9a}) || n != 8 {
+		t.Errorf("got %#v %v, want %#v %v", b, n, []byte{0x9a}, 8)
+	}
+	d, dn, err := byteman.DecodeDifferentialManchester(b, n, byteman.BitOrderMSB, 0)
+	if err != nil || dn != 4 || !bytes.Equal(d, []byte{0x60}) {
+		t.Errorf("got %#v %v %v, want %#v %v", d, dn, err, []byte{0x60}, 4)
+	}
+
+	// The encoding is polarity insensitive.
+	inv := byteman.Not(b)
+	if d, _, err := byteman.DecodeDifferentialManchester(inv, n, byteman.BitOrderMSB, 1); err != nil || !bytes.Equal(d, []byte{0x60}) {
+		t.Errorf("got %#v %v, want %#v", d, err, []byte{0x60})
+	}
+
+	data := []byte{0xde, 0xad, 0xbe}
+	b, n = byteman.EncodeDifferentialManchester(data, 21, byteman.BitOrderLSB, 1)
+	if d, dn, err := byteman.DecodeDifferentialManchester(b, n, byteman.BitOrderLSB, 1); err != nil || dn != 21 || !bytes.Equal(d, []byte{0xde, 0xad, 0x1e}) {
+		t.Errorf("got %#v %v %v, want %#v %v", d, dn, err, []byte{0xde, 0xad, 0x1e}, 21)
+	}
+
+	if _, _, err := byteman.DecodeDifferentialManchester([]byte{0x9c}, 8, byteman.BitOrderMSB, 0); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.FrameError); !ok || e.Pos != 4 {
+		t.Errorf("got %v, want bit position 4", err)
+	}
+}
+
+func TestNRZI(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.NRZIMode
+		arg3 uint8
+		out  []byte
+	}{
+		{[]byte{0x00}, 4, byteman.NRZIToggleOnZero, 1, []byte{0x50}},
+		{[]byte{0xf0}, 4, byteman.NRZIToggleOnZero, 1, []byte{0xf0}},
+		{[]byte{0xa0}, 4, byteman.NRZIToggleOnZero, 0, []byte{0x60}},
+		{[]byte{0xa0}, 4, byteman.NRZIToggleOnOne, 0, []byte{0xc0}},
+	}
+	for _, v := range table {
+		b, n := byteman.EncodeNRZI(v.arg0, v.arg1, byteman.BitOrderMSB, v.arg2, v.arg3)
+		if !bytes.Equal(b, v.out) || n != v.arg1 {
+			t.Errorf("got %#v %v, want %#v %v", b, n, v.out, v.arg1)
+		}
+		if d, dn := byteman.DecodeNRZI(b, n, byteman.BitOrderMSB, v.arg2, v.arg3); !bytes.Equal(d, v.arg0) || dn != v.arg1 {
+			t.Errorf("got %#v %v, want %#v %v", d, dn, v.arg0, v.arg1)
+		}
+	}
+}
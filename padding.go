@@ -0,0 +1,111 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+)
+
+// PaddingScheme represents the block cipher padding scheme.
+type PaddingScheme uint8
+
+const (
+	// PaddingSchemePKCS7 represents the PKCS#7 (RFC 5652) padding scheme (i.e. 04 04 04 04).
+	PaddingSchemePKCS7 PaddingScheme = 0
+	// PaddingSchemeANSIX923 represents the ANSI X.923 padding scheme (i.e. 00 00 00 04).
+	PaddingSchemeANSIX923 PaddingScheme = 1
+	// PaddingSchemeISO7816 represents the ISO/IEC 7816-4 padding scheme (i.e. 80 00 00 00).
+	PaddingSchemeISO7816 PaddingScheme = 2
+	// PaddingSchemeISO10126 represents the ISO 10126 padding scheme (i.e. 9f 3a c2 04).
+	PaddingSchemeISO10126 PaddingScheme = 3
+)
+
+// Pad returns the padded byte slice by the given block size and padding scheme.
+// A full block of padding is added if the byte slice length is already a multiple of the block size.
+func Pad(b []byte, blockSize int, scheme PaddingScheme) ([]byte, error) {
+	if err := checkBlockSize(blockSize, scheme); err != nil {
+		return nil, err
+	}
+	n := blockSize - len(b)%blockSize
+	switch scheme {
+	case PaddingSchemePKCS7:
+		return ResizeWith(b, len(b)+n, ResizeOptions{Fill: byte(n)}), nil
+	case PaddingSchemeANSIX923:
+		pb := Resize(b, len(b)+n)
+		pb[len(pb)-1] = byte(n)
+		return pb, nil
+	case PaddingSchemeISO7816:
+		pb := Resize(b, len(b)+n)
+		pb[len(b)] = 0x80
+		return pb, nil
+	default:
+		pb := Resize(b, len(b)+n)
+		if _, err := rand.Read(pb[len(b) : len(pb)-1]); err != nil {
+			return nil, err
+		}
+		pb[len(pb)-1] = byte(n)
+		return pb, nil
+	}
+}
+
+// Unpad returns the unpadded byte slice by the given block size and padding scheme.
+// The padding is validated in constant time and ErrInvalidPadding is returned for any invalid padding.
+// The returned byte slice shares the underlying array of the given byte slice.
+func Unpad(b []byte, blockSize int, scheme PaddingScheme) ([]byte, error) {
+	if err := checkBlockSize(blockSize, scheme); err != nil {
+		return nil, err
+	}
+	if len(b) == 0 || len(b)%blockSize != 0 {
+		return nil, ErrInvalidPadding
+	}
+	block := b[len(b)-blockSize:]
+	last := block[blockSize-1]
+	n := int(last)
+
+	// valid is 1 if the padding is valid, otherwise 0.
+	valid := subtle.ConstantTimeLessOrEq(1, n) & subtle.ConstantTimeLessOrEq(n, blockSize)
+	switch scheme {
+	case PaddingSchemePKCS7, PaddingSchemeANSIX923:
+		want := last
+		if scheme == PaddingSchemeANSIX923 {
+			want = 0
+		}
+		for i := 1; i < blockSize; i++ {
+			// inPad is 1 if the byte is a padding byte.
+			inPad := subtle.ConstantTimeLessOrEq(i+1, n)
+			ok := subtle.ConstantTimeByteEq(block[blockSize-1-i], want)
+			valid &= subtle.ConstantTimeSelect(inPad, ok, 1)
+		}
+	case PaddingSchemeISO7816:
+		found, invalid := 0, 0
+		n = 0
+		for i := 0; i < blockSize; i++ {
+			c := block[blockSize-1-i]
+			searching := found ^ 1
+			marker := subtle.ConstantTimeByteEq(c, 0x80)
+			zero := subtle.ConstantTimeByteEq(c, 0x00)
+			n = subtle.ConstantTimeSelect(searching&marker, i+1, n)
+			invalid |= searching & ((zero | marker) ^ 1)
+			found |= marker
+		}
+		valid = found & (invalid ^ 1)
+	}
+	if valid != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return b[:len(b)-n], nil
+}
+
+// checkBlockSize checks the given block size by the given padding scheme.
+func checkBlockSize(blockSize int, scheme PaddingScheme) error {
+	if blockSize < 1 || (blockSize > 255 && scheme != PaddingSchemeISO7816) {
+		return fmt.Errorf("byteman: invalid block size %d", blockSize)
+	}
+	if scheme > PaddingSchemeISO10126 {
+		return fmt.Errorf("byteman: invalid padding scheme %d", scheme)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestPad(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.PaddingScheme
+		out  []byte
+	}{
+		{[]byte("foo"), 4, byteman.PaddingSchemePKCS7, []byte{0x66, 0x6f, 0x6f, 0x01}},
+		{[]byte("foo"), 8, byteman.PaddingSchemePKCS7, []byte{0x66, 0x6f, 0x6f, 0x05, 0x05, 0x05, 0x05, 0x05}},
+		{[]byte("food"), 4, byteman.PaddingSchemePKCS7, []byte{0x66, 0x6f, 0x6f, 0x64, 0x04, 0x04, 0x04, 0x04}},
+		{[]byte{}, 2, byteman.PaddingSchemePKCS7, []byte{0x02, 0x02}},
+		{[]byte("foo"), 8, byteman.PaddingSchemeANSIX923, []byte{0x66, 0x6f, 0x6f, 0x00, 0x00, 0x00, 0x00, 0x05}},
+		{[]byte("foo"), 8, byteman.PaddingSchemeISO7816, []byte{0x66, 0x6f, 0x6f, 0x80, 0x00, 0x00, 0x00, 0x00}},
+		{[]byte("foo"), 4, byteman.PaddingSchemeISO7816, []byte{0x66, 0x6f, 0x6f, 0x80}},
+	}
+	for _, v := range table {
+		b, err := byteman.Pad(v.arg0, v.arg1, v.arg2)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	b, err := byteman.Pad([]byte("foo"), 8, byteman.PaddingSchemeISO10126)
+	if err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if len(b) != 8 || !bytes.Equal(b[:3], []byte("foo")) || b[7] != 0x05 {
+		t.Errorf("got %v, want foo + 4 random bytes + 0x05", b)
+	}
+
+	for _, v := range []int{0, 256} {
+		if _, err := byteman.Pad([]byte("foo"), v, byteman.PaddingSchemePKCS7); err == nil {
+			t.Error("got nil, want error")
+		}
+	}
+	if _, err := byteman.Pad([]byte("foo"), 256, byteman.PaddingSchemeISO7816); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+func BenchmarkPad(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.Pad([]byte("foo"), 16, byteman.PaddingSchemePKCS7)
+	}
+}
+
+func TestUnpad(t *testing.T) {
+	schemes := []byteman.PaddingScheme{byteman.PaddingSchemePKCS7, byteman.PaddingSchemeANSIX923, byteman.PaddingSchemeISO7816, byteman.PaddingSchemeISO10126}
+	for _, scheme := range schemes {
+		for _, size := range []int{1, 8, 16} {
+			for n := 0; n <= 2*size; n++ {
+				data := bytes.Repeat([]byte{0x80}, n)
+				padded, err := byteman.Pad(data, size, scheme)
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+					continue
+				}
+				if b, err := byteman.Unpad(padded, size, scheme); err != nil {
+					t.Errorf("got %v, want nil", err)
+				} else if !bytes.Equal(b, data) {
+					t.Errorf("got %v, want %v", b, data)
+				}
+			}
+		}
+	}
+
+	errs := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.PaddingScheme
+	}{
+		{[]byte{}, 4, byteman.PaddingSchemePKCS7},
+		{[]byte{0x66, 0x6f, 0x01}, 4, byteman.PaddingSchemePKCS7},
+		{[]byte{0x66, 0x6f, 0x6f, 0x00}, 4, byteman.PaddingSchemePKCS7},
+		{[]byte{0x66, 0x6f, 0x6f, 0x05}, 4, byteman.PaddingSchemePKCS7},
+		{[]byte{0x66, 0x6f, 0x03, 0x02, 0x03}, 5, byteman.PaddingSchemePKCS7},
+		{[]byte{0x66, 0x6f, 0x01, 0x00, 0x03}, 5, byteman.PaddingSchemeANSIX923},
+		{[]byte{0x66, 0x6f, 0x00, 0x00, 0x00}, 5, byteman.PaddingSchemeANSIX923},
+		{[]byte{0x66, 0x6f, 0x00, 0x00, 0x00}, 5, byteman.PaddingSchemeISO7816},
+		{[]byte{0x66, 0x80, 0x00, 0x01, 0x00}, 5, byteman.PaddingSchemeISO7816},
+		{[]byte{0x66, 0x6f, 0x6f, 0x06}, 4, byteman.PaddingSchemeISO10126},
+	}
+	for _, v := range errs {
+		if _, err := byteman.Unpad(v.arg0, v.arg1, v.arg2); err != byteman.ErrInvalidPadding {
+			t.Errorf("got %v, want %v", err, byteman.ErrInvalidPadding)
+		}
+	}
+}
+
+func BenchmarkUnpad(b *testing.B) {
+	data, _ := byteman.Pad([]byte("foo"), 16, byteman.PaddingSchemePKCS7)
+	for i := 0; i < b.N; i++ {
+		byteman.Unpad(data, 16, byteman.PaddingSchemePKCS7)
+	}
+}
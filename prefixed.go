@@ -0,0 +1,170 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PrefixType represents the length prefix type.
+type PrefixType uint8
+
+const (
+	// PrefixTypeUint8 represents the 1 byte length prefix (i.e. Pascal strings).
+	PrefixTypeUint8 PrefixType = 0
+	// PrefixTypeUint16 represents the 2 byte length prefix.
+	PrefixTypeUint16 PrefixType = 1
+	// PrefixTypeUint32 represents the 4 byte length prefix.
+	PrefixTypeUint32 PrefixType = 2
+	// PrefixTypeVarint represents the unsigned LEB128 varint length prefix. The byte order is ignored.
+	PrefixTypeVarint PrefixType = 3
+	// PrefixTypeRESP represents the Redis (RESP) bulk string framing with an ASCII decimal length
+	// (i.e. `$5\r\nhello\r\n`). The byte order is ignored.
+	PrefixTypeRESP PrefixType = 4
+)
+
+// maxRESPDigits represents the maximum number of RESP bulk string length digits.
+const maxRESPDigits = 19
+
+// FromPrefixedBytes returns a length-prefixed byte slice by the given byte slice, prefix type and byte order (endianness).
+func FromPrefixedBytes(b []byte, prefix PrefixType, bo ByteOrder) ([]byte, error) {
+	var p []byte
+	switch prefix {
+	case PrefixTypeUint8:
+		if len(b) > 0xff {
+			return nil, fmt.Errorf("byteman: length %d overflows uint8 prefix", len(b))
+		}
+		p = FromUint(uint8(len(b)), bo)
+	case PrefixTypeUint16:
+		if len(b) > 0xffff {
+			return nil, fmt.Errorf("byteman: length %d overflows uint16 prefix", len(b))
+		}
+		p = FromUint(uint16(len(b)), bo)
+	case PrefixTypeUint32:
+		if uint64(len(b)) > 0xffffffff {
+			return nil, fmt.Errorf("byteman: length %d overflows uint32 prefix", len(b))
+		}
+		p = FromUint(uint32(len(b)), bo)
+	case PrefixTypeVarint:
+		p = make([]byte, binary.MaxVarintLen64)
+		p = p[:binary.PutUvarint(p, uint64(len(b)))]
+	case PrefixTypeRESP:
+		p = []byte("$" + strconv.Itoa(len(b)) + "\r\n")
+		return Combine(p, b, []byte("\r\n")), nil
+	default:
+		return nil, fmt.Errorf("byteman: invalid prefix type %d", prefix)
+	}
+	return Combine(p, b), nil
+}
+
+// FromPrefixedString returns a length-prefixed byte slice by the given string, prefix type and byte order (endianness).
+func FromPrefixedString(str string, prefix PrefixType, bo ByteOrder) ([]byte, error) {
+	return FromPrefixedBytes([]byte(str), prefix, bo)
+}
+
+// PrefixedBytes returns the value of the length-prefixed byte slice by the given prefix type, byte order (endianness)
+// and maximum length (zero or negative means no limit). It also returns the number of bytes read (prefix and value).
+// The returned value shares the underlying array of the given byte slice.
+// The RESP null bulk string (`$-1\r\n`) returns a nil value.
+func PrefixedBytes(b []byte, prefix PrefixType, bo ByteOrder, max int) ([]byte, int, error) {
+	if prefix == PrefixTypeRESP {
+		return prefixedRESP(b, max)
+	}
+	var (
+		length uint64
+		n      int
+	)
+	switch prefix {
+	case PrefixTypeUint8:
+		n = 1
+	case PrefixTypeUint16:
+		n = 2
+	case PrefixTypeUint32:
+		n = 4
+	case PrefixTypeVarint:
+		if length, n = binary.Uvarint(b); n == 0 {
+			return nil, 0, io.ErrUnexpectedEOF
+		} else if n < 0 {
+			return nil, 0, errors.New("byteman: varint length prefix overflows 64 bits")
+		}
+	default:
+		return nil, 0, fmt.Errorf("byteman: invalid prefix type %d", prefix)
+	}
+	if len(b) < n {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	switch prefix {
+	case PrefixTypeUint8:
+		length = uint64(b[0])
+	case PrefixTypeUint16:
+		length = uint64(Uint16(b[:2], bo))
+	case PrefixTypeUint32:
+		length = uint64(Uint32(b[:4], bo))
+	}
+
+	available := len(b) - n
+	if (max > 0 && length > uint64(max)) || length > uint64(available) {
+		return nil, 0, &LengthError{Declared: length, Available: available, Max: max}
+	}
+	return b[n : n+int(length)], n + int(length), nil
+}
+
+// PrefixedString returns the value of the length-prefixed byte slice as a string. See PrefixedBytes.
+func PrefixedString(b []byte, prefix PrefixType, bo ByteOrder, max int) (string, int, error) {
+	v, n, err := PrefixedBytes(b, prefix, bo, max)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(v), n, nil
+}
+
+// prefixedRESP returns the value of the RESP bulk string by the given byte slice and maximum length.
+func prefixedRESP(b []byte, max int) ([]byte, int, error) {
+	if len(b) == 0 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if b[0] != '$' {
+		return nil, 0, fmt.Errorf("byteman: invalid RESP bulk string marker %#02x", b[0])
+	}
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		if len(b) > maxRESPDigits+3 {
+			return nil, 0, errors.New("byteman: RESP bulk string length is too long")
+		}
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if i < 2 || b[i-1] != '\r' {
+		return nil, 0, errors.New("byteman: RESP bulk string length is not terminated by CRLF")
+	}
+	digits := string(b[1 : i-1])
+	if digits == "-1" {
+		return nil, i + 1, nil
+	}
+	if len(digits) == 0 || len(digits) > maxRESPDigits || (len(digits) > 1 && digits[0] == '0') {
+		return nil, 0, fmt.Errorf("byteman: invalid RESP bulk string length %q", digits)
+	}
+	length, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("byteman: invalid RESP bulk string length %q", digits)
+	}
+
+	n := i + 1
+	available := len(b) - n
+	if (max > 0 && length > uint64(max)) || length > uint64(available) {
+		return nil, 0, &LengthError{Declared: length, Available: available, Max: max}
+	}
+	end := n + int(length)
+	if len(b) < end+2 {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if b[end] != '\r' || b[end+1] != '\n' {
+		return nil, 0, errors.New("byteman: RESP bulk string value is not terminated by CRLF")
+	}
+	return b[n:end], end + 2, nil
+}
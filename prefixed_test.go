@@ -0,0 +1,127 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestFromPrefixedBytes(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 byteman.PrefixType
+		arg2 byteman.ByteOrder
+		out  []byte
+	}{
+		{[]byte("foo"), byteman.PrefixTypeUint8, &byteman.BigEndian{}, []byte{0x03, 0x66, 0x6f, 0x6f}},
+		{[]byte("foo"), byteman.PrefixTypeUint16, &byteman.BigEndian{}, []byte{0x00, 0x03, 0x66, 0x6f, 0x6f}},
+		{[]byte("foo"), byteman.PrefixTypeUint16, &byteman.LittleEndian{}, []byte{0x03, 0x00, 0x66, 0x6f, 0x6f}},
+		{[]byte("foo"), byteman.PrefixTypeUint32, &byteman.BigEndian{}, []byte{0x00, 0x00, 0x00, 0x03, 0x66, 0x6f, 0x6f}},
+		{[]byte("foo"), byteman.PrefixTypeUint32, &byteman.LittleEndian{}, []byte{0x03, 0x00, 0x00, 0x00, 0x66, 0x6f, 0x6f}},
+		{[]byte("foo"), byteman.PrefixTypeVarint, &byteman.LittleEndian{}, []byte{0x03, 0x66, 0x6f, 0x6f}},
+		{make([]byte, 300), byteman.PrefixTypeVarint, &byteman.BigEndian{}, append([]byte{0xac, 0x02}, make([]byte, 300)...)},
+		{[]byte{}, byteman.PrefixTypeUint16, &byteman.BigEndian{}, []byte{0x00, 0x00}},
+		{[]byte("hello"), byteman.PrefixTypeRESP, &byteman.BigEndian{}, []byte("$5\r\nhello\r\n")},
+		{[]byte{}, byteman.PrefixTypeRESP, &byteman.BigEndian{}, []byte("$0\r\n\r\n")},
+	}
+	for _, v := range table {
+		b, err := byteman.FromPrefixedBytes(v.arg0, v.arg1, v.arg2)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if _, err := byteman.FromPrefixedBytes(make([]byte, 256), byteman.PrefixTypeUint8, &byteman.BigEndian{}); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.FromPrefixedString(string(make([]byte, 65536)), byteman.PrefixTypeUint16, &byteman.BigEndian{}); err == nil {
+		t.Error("got nil, want error")
+	}
+	if b, err := byteman.FromPrefixedString("foo", byteman.PrefixTypeUint8, &byteman.BigEndian{}); err != nil || !bytes.Equal(b, []byte("\x03foo")) {
+		t.Errorf("got %v, %v, want %v", b, err, []byte("\x03foo"))
+	}
+}
+
+func BenchmarkFromPrefixedBytes(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.FromPrefixedBytes([]byte("foo"), byteman.PrefixTypeUint16, &byteman.BigEndian{})
+	}
+}
+
+func TestPrefixedBytes(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 byteman.PrefixType
+		arg2 byteman.ByteOrder
+		out  []byte
+		n    int
+	}{
+		{[]byte{0x03, 0x66, 0x6f, 0x6f, 0xff}, byteman.PrefixTypeUint8, &byteman.BigEndian{}, []byte("foo"), 4},
+		{[]byte{0x00, 0x03, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint16, &byteman.BigEndian{}, []byte("foo"), 5},
+		{[]byte{0x03, 0x00, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint16, &byteman.LittleEndian{}, []byte("foo"), 5},
+		{[]byte{0x03, 0x00, 0x00, 0x00, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint32, &byteman.LittleEndian{}, []byte("foo"), 7},
+		{[]byte{0x03, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeVarint, &byteman.BigEndian{}, []byte("foo"), 4},
+		{[]byte{0x00}, byteman.PrefixTypeUint8, &byteman.BigEndian{}, []byte{}, 1},
+		{[]byte("$5\r\nhello\r\n+OK"), byteman.PrefixTypeRESP, &byteman.BigEndian{}, []byte("hello"), 11},
+		{[]byte("$0\r\n\r\n"), byteman.PrefixTypeRESP, &byteman.BigEndian{}, []byte{}, 6},
+		{[]byte("$-1\r\n"), byteman.PrefixTypeRESP, &byteman.BigEndian{}, nil, 5},
+	}
+	for _, v := range table {
+		b, n, err := byteman.PrefixedBytes(v.arg0, v.arg1, v.arg2, 0)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) || n != v.n {
+			t.Errorf("got %v (%d), want %v (%d)", b, n, v.out, v.n)
+		}
+	}
+
+	if s, n, err := byteman.PrefixedString([]byte{0x00, 0x03, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint16, &byteman.BigEndian{}, 3); err != nil || s != "foo" || n != 5 {
+		t.Errorf("got %v, %v, %v, want %v", s, n, err, "foo")
+	}
+
+	errs := []struct {
+		arg0 []byte
+		arg1 byteman.PrefixType
+		arg2 int
+		out  error
+	}{
+		{[]byte{}, byteman.PrefixTypeUint8, 0, io.ErrUnexpectedEOF},
+		{[]byte{0x00}, byteman.PrefixTypeUint16, 0, io.ErrUnexpectedEOF},
+		{[]byte{0x80}, byteman.PrefixTypeVarint, 0, io.ErrUnexpectedEOF},
+		{[]byte{0x04, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint8, 0, &byteman.LengthError{Declared: 4, Available: 3}},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0x66}, byteman.PrefixTypeUint32, 0, &byteman.LengthError{Declared: 0xffffffff, Available: 1}},
+		{[]byte{0x03, 0x66, 0x6f, 0x6f}, byteman.PrefixTypeUint8, 2, &byteman.LengthError{Declared: 3, Available: 3, Max: 2}},
+		{[]byte("$5\r\nhel"), byteman.PrefixTypeRESP, 0, &byteman.LengthError{Declared: 5, Available: 3}},
+		{[]byte("$5\r\nhello\r\n"), byteman.PrefixTypeRESP, 4, &byteman.LengthError{Declared: 5, Available: 7, Max: 4}},
+		{[]byte("$5\r\nhello"), byteman.PrefixTypeRESP, 0, io.ErrUnexpectedEOF},
+		{[]byte("$12"), byteman.PrefixTypeRESP, 0, io.ErrUnexpectedEOF},
+	}
+	for _, v := range errs {
+		_, _, err := byteman.PrefixedBytes(v.arg0, v.arg1, &byteman.BigEndian{}, v.arg2)
+		if err == nil || err.Error() != v.out.Error() {
+			t.Errorf("got %v, want %v", err, v.out)
+		}
+	}
+	if _, _, err := byteman.PrefixedBytes(bytes.Repeat([]byte{0xff}, 11), byteman.PrefixTypeVarint, &byteman.BigEndian{}, 0); err == nil {
+		t.Error("got nil, want error")
+	}
+	for _, v := range []string{"5\r\nhello\r\n", "$\r\n\r\n", "$05\r\nhello\r\n", "$+5\r\nhello\r\n", "$5\nhello\r\n", "$5\r\nhelloXX"} {
+		if _, _, err := byteman.PrefixedBytes([]byte(v), byteman.PrefixTypeRESP, &byteman.BigEndian{}, 0); err == nil {
+			t.Errorf("got nil, want error for %q", v)
+		}
+	}
+}
+
+func BenchmarkPrefixedBytes(b *testing.B) {
+	data := []byte{0x00, 0x03, 0x66, 0x6f, 0x6f}
+	for i := 0; i < b.N; i++ {
+		byteman.PrefixedBytes(data, byteman.PrefixTypeUint16, &byteman.BigEndian{}, 0)
+	}
+}
@@ -0,0 +1,469 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxProtoField represents the maximum Protocol Buffers field number.
+	maxProtoField = 1<<29 - 1
+	// maxProtoDepth represents the maximum nesting depth of groups and messages.
+	maxProtoDepth = 64
+)
+
+// ProtoWireType represents the Protocol Buffers wire type.
+type ProtoWireType uint8
+
+const (
+	// ProtoVarint represents the varint wire type (int32, int64, uint32, uint64, sint32, sint64, bool, enum).
+	ProtoVarint ProtoWireType = 0
+	// ProtoFixed64 represents the 64 bit wire type (fixed64, sfixed64, double).
+	ProtoFixed64 ProtoWireType = 1
+	// ProtoBytes represents the length-delimited wire type (string, bytes, messages, packed fields).
+	ProtoBytes ProtoWireType = 2
+	// ProtoStartGroup represents the start group wire type (deprecated).
+	ProtoStartGroup ProtoWireType = 3
+	// ProtoEndGroup represents the end group wire type (deprecated).
+	ProtoEndGroup ProtoWireType = 4
+	// ProtoFixed32 represents the 32 bit wire type (fixed32, sfixed32, float).
+	ProtoFixed32 ProtoWireType = 5
+)
+
+// String returns the name of the wire type.
+func (t ProtoWireType) String() string {
+	switch t {
+	case ProtoVarint:
+		return "varint"
+	case ProtoFixed64:
+		return "fixed64"
+	case ProtoBytes:
+		return "bytes"
+	case ProtoStartGroup:
+		return "group"
+	case ProtoEndGroup:
+		return "endgroup"
+	case ProtoFixed32:
+		return "fixed32"
+	}
+	return "wiretype(" + strconv.Itoa(int(t)) + ")"
+}
+
+// EncodeZigZag returns the ZigZag encoding (sint32 and sint64) of the given signed value.
+func EncodeZigZag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// DecodeZigZag returns the signed value by the given ZigZag encoded value.
+func DecodeZigZag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// AppendProtoVarint appends the varint encoding of the given value.
+func AppendProtoVarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+// AppendProtoTag appends the field tag by the given field number and wire type.
+func AppendProtoTag(b []byte, field uint32, wt ProtoWireType) []byte {
+	return AppendProtoVarint(b, uint64(field)<<3|uint64(wt&7))
+}
+
+// ReadProtoVarint returns the varint value and its size by the given byte slice.
+// It returns io.ErrUnexpectedEOF for a truncated varint.
+func ReadProtoVarint(b []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	} else if n < 0 {
+		return 0, 0, &FrameError{Pos: -n - 1, Msg: "varint overflows 64 bits"}
+	}
+	return v, n, nil
+}
+
+// ReadProtoTag returns the field number, wire type and tag size by the given byte slice.
+// It returns io.ErrUnexpectedEOF for a truncated tag and *FrameError for an invalid field number or wire type.
+func ReadProtoTag(b []byte) (uint32, ProtoWireType, int, error) {
+	return readProtoTag(b, 0)
+}
+
+// readProtoTag returns the field tag at the given offset.
+func readProtoTag(b []byte, i int) (uint32, ProtoWireType, int, error) {
+	v, n, err := ReadProtoVarint(b[i:])
+	if err != nil {
+		if e, ok := err.(*FrameError); ok {
+			e.Pos += i
+		}
+		return 0, 0, 0, err
+	}
+	field, wt := v>>3, ProtoWireType(v&7)
+	if field == 0 || field > maxProtoField {
+		return 0, 0, 0, &FrameError{Pos: i, Msg: fmt.Sprintf("invalid field number %d", field)}
+	}
+	if wt > ProtoFixed32 {
+		return 0, 0, 0, &FrameError{Pos: i, Msg: fmt.Sprintf("invalid wire type %d", wt)}
+	}
+	return uint32(field), wt, n, nil
+}
+
+// ProtoField represents a Protocol Buffers wire-format field.
+type ProtoField struct {
+	// Number is the field number.
+	Number uint32
+	// Type is the wire type.
+	Type ProtoWireType
+	// Value is the value of varint, fixed32 and fixed64 fields.
+	Value uint64
+	// Bytes is the value of length-delimited fields or the content of groups (without the end group tag).
+	// It shares the underlying array of the read byte slice.
+	Bytes []byte
+	// Offset is the byte offset of the field (tag).
+	Offset int
+	// ValueOffset is the byte offset of the value (after the length of length-delimited fields).
+	ValueOffset int
+	// End is the byte offset after the field (and the end group tag of groups).
+	End int
+}
+
+// readProtoField returns the field at the given offset by the given byte slice and group depth.
+func readProtoField(b []byte, i, depth int) (ProtoField, error) {
+	num, wt, n, err := readProtoTag(b, i)
+	if err != nil {
+		return ProtoField{}, err
+	}
+	f := ProtoField{Number: num, Type: wt, Offset: i, ValueOffset: i + n}
+	j := i + n
+	switch wt {
+	case ProtoVarint:
+		v, n, err := ReadProtoVarint(b[j:])
+		if err != nil {
+			if e, ok := err.(*FrameError); ok {
+				e.Pos += j
+			}
+			return ProtoField{}, err
+		}
+		f.Value, j = v, j+n
+	case ProtoFixed64:
+		if len(b)-j < 8 {
+			return ProtoField{}, io.ErrUnexpectedEOF
+		}
+		f.Value, j = Uint64(b[j:j+8], &LittleEndian{}), j+8
+	case ProtoFixed32:
+		if len(b)-j < 4 {
+			return ProtoField{}, io.ErrUnexpectedEOF
+		}
+		f.Value, j = uint64(Uint32(b[j:j+4], &LittleEndian{})), j+4
+	case ProtoBytes:
+		length, n, err := ReadProtoVarint(b[j:])
+		if err != nil {
+			if e, ok := err.(*FrameError); ok {
+				e.Pos += j
+			}
+			return ProtoField{}, err
+		}
+		j += n
+		if available := len(b) - j; length > uint64(available) {
+			return ProtoField{}, &LengthError{Declared: length, Available: available}
+		}
+		f.ValueOffset, f.Bytes, j = j, b[j:j+int(length)], j+int(length)
+	case ProtoStartGroup:
+		if depth >= maxProtoDepth {
+			return ProtoField{}, fmt.Errorf("byteman: protobuf nesting exceeds %d levels", maxProtoDepth)
+		}
+		for k := j; ; {
+			if k >= len(b) {
+				return ProtoField{}, io.ErrUnexpectedEOF
+			}
+			g, err := readProtoField(b, k, depth+1)
+			if err != nil {
+				return ProtoField{}, err
+			}
+			if g.Type == ProtoEndGroup {
+				if g.Number != num {
+					return ProtoField{}, &FrameError{Pos: k, Msg: fmt.Sprintf("end group %d does not match group %d", g.Number, num)}
+				}
+				f.Bytes, j = b[j:k], g.End
+				break
+			}
+			k = g.End
+		}
+	}
+	f.End = j
+	return f, nil
+}
+
+// ProtoReader represents a Protocol Buffers wire-format field reader.
+type ProtoReader struct {
+	b   []byte
+	pos int
+}
+
+// NewProtoReader returns a new Protocol Buffers wire-format reader by the given byte slice.
+func NewProtoReader(b []byte) *ProtoReader {
+	return &ProtoReader{b: b}
+}
+
+// Next returns the next field. Groups are returned as a single field with their content.
+// It returns io.EOF at the end of the input, io.ErrUnexpectedEOF for truncated fields,
+// *LengthError for lengths which exceed the available bytes and *FrameError for malformed fields.
+func (r *ProtoReader) Next() (ProtoField, error) {
+	if r.pos >= len(r.b) {
+		return ProtoField{}, io.EOF
+	}
+	f, err := readProtoField(r.b, r.pos, 0)
+	if err != nil {
+		return ProtoField{}, err
+	}
+	if f.Type == ProtoEndGroup {
+		return ProtoField{}, &FrameError{Pos: f.Offset, Msg: fmt.Sprintf("unexpected end group %d", f.Number)}
+	}
+	r.pos = f.End
+	return f, nil
+}
+
+// Offset returns the byte offset of the next field.
+func (r *ProtoReader) Offset() int {
+	return r.pos
+}
+
+// ProtoBuilder represents a Protocol Buffers wire-format builder which computes the lengths of nested messages.
+type ProtoBuilder struct {
+	frames [][]byte
+	fields []uint32
+	err    error
+}
+
+// NewProtoBuilder returns a new Protocol Buffers wire-format builder.
+func NewProtoBuilder() *ProtoBuilder {
+	return &ProtoBuilder{frames: [][]byte{nil}}
+}
+
+// AddVarint adds a varint field (uint32, uint64, bool, enum) by the given field number and value.
+func (pb *ProtoBuilder) AddVarint(field uint32, v uint64) *ProtoBuilder {
+	if pb.tag(field, ProtoVarint) {
+		pb.frames[len(pb.frames)-1] = AppendProtoVarint(pb.frames[len(pb.frames)-1], v)
+	}
+	return pb
+}
+
+// AddInt adds a varint field (int32, int64) by the given field number and value.
+// Negative values are encoded in 10 bytes.
+func (pb *ProtoBuilder) AddInt(field uint32, v int64) *ProtoBuilder {
+	return pb.AddVarint(field, uint64(v))
+}
+
+// AddSint adds a ZigZag encoded varint field (sint32, sint64) by the given field number and value.
+func (pb *ProtoBuilder) AddSint(field uint32, v int64) *ProtoBuilder {
+	return pb.AddVarint(field, EncodeZigZag(v))
+}
+
+// AddFixed32 adds a 32 bit field (fixed32, sfixed32, float bits) by the given field number and value.
+func (pb *ProtoBuilder) AddFixed32(field uint32, v uint32) *ProtoBuilder {
+	if pb.tag(field, ProtoFixed32) {
+		pb.frames[len(pb.frames)-1] = append(pb.frames[len(pb.frames)-1], FromUint(v, &LittleEndian{})...)
+	}
+	return pb
+}
+
+// AddFixed64 adds a 64 bit field (fixed64, sfixed64, double bits) by the given field number and value.
+func (pb *ProtoBuilder) AddFixed64(field uint32, v uint64) *ProtoBuilder {
+	if pb.tag(field, ProtoFixed64) {
+		pb.frames[len(pb.frames)-1] = append(pb.frames[len(pb.frames)-1], FromUint(v, &LittleEndian{})...)
+	}
+	return pb
+}
+
+// AddBytes adds a length-delimited field (bytes, string, encoded message, packed field) by the given field number and value.
+func (pb *ProtoBuilder) AddBytes(field uint32, v []byte) *ProtoBuilder {
+	if pb.tag(field, ProtoBytes) {
+		top := len(pb.frames) - 1
+		pb.frames[top] = AppendProtoVarint(pb.frames[top], uint64(len(v)))
+		pb.frames[top] = append(pb.frames[top], v...)
+	}
+	return pb
+}
+
+// AddString adds a length-delimited string field by the given field number and value.
+func (pb *ProtoBuilder) AddString(field uint32, v string) *ProtoBuilder {
+	return pb.AddBytes(field, []byte(v))
+}
+
+// Begin begins a nested message by the given field number. The following fields are nested until End is called.
+func (pb *ProtoBuilder) Begin(field uint32) *ProtoBuilder {
+	if pb.err != nil {
+		return pb
+	}
+	if len(pb.fields) >= maxProtoDepth {
+		pb.err = fmt.Errorf("byteman: protobuf nesting exceeds %d levels", maxProtoDepth)
+		return pb
+	}
+	pb.frames = append(pb.frames, nil)
+	pb.fields = append(pb.fields, field)
+	return pb
+}
+
+// End ends the last begun nested message.
+func (pb *ProtoBuilder) End() *ProtoBuilder {
+	if pb.err != nil {
+		return pb
+	}
+	if len(pb.fields) == 0 {
+		pb.err = errors.New("byteman: protobuf End without Begin")
+		return pb
+	}
+	value, field := pb.frames[len(pb.frames)-1], pb.fields[len(pb.fields)-1]
+	pb.frames, pb.fields = pb.frames[:len(pb.frames)-1], pb.fields[:len(pb.fields)-1]
+	return pb.AddBytes(field, value)
+}
+
+// StartGroup adds a start group tag by the given field number.
+func (pb *ProtoBuilder) StartGroup(field uint32) *ProtoBuilder {
+	pb.tag(field, ProtoStartGroup)
+	return pb
+}
+
+// EndGroup adds an end group tag by the given field number.
+func (pb *ProtoBuilder) EndGroup(field uint32) *ProtoBuilder {
+	pb.tag(field, ProtoEndGroup)
+	return pb
+}
+
+// Bytes returns the encoded message. It returns an error if a field number is invalid
+// or a nested message is not ended.
+func (pb *ProtoBuilder) Bytes() ([]byte, error) {
+	if pb.err != nil {
+		return nil, pb.err
+	}
+	if len(pb.fields) > 0 {
+		return nil, fmt.Errorf("byteman: protobuf field %d is not ended", pb.fields[len(pb.fields)-1])
+	}
+	return pb.frames[0], nil
+}
+
+// tag appends the field tag by the given field number and wire type. It returns false on error.
+func (pb *ProtoBuilder) tag(field uint32, wt ProtoWireType) bool {
+	if pb.err != nil {
+		return false
+	}
+	if field == 0 || field > maxProtoField {
+		pb.err = fmt.Errorf("byteman: invalid protobuf field number %d", field)
+		return false
+	}
+	top := len(pb.frames) - 1
+	pb.frames[top] = AppendProtoTag(pb.frames[top], field, wt)
+	return true
+}
+
+// ProtoNode represents a schemaless decoded Protocol Buffers field.
+type ProtoNode struct {
+	ProtoField
+	// Children is the decoded content of groups and length-delimited values which parse as messages.
+	Children []*ProtoNode
+}
+
+// ParseProto returns the schemaless decoded message tree by the given wire-format byte slice.
+// Length-delimited values are decoded as nested messages if they parse completely, so strings and
+// packed fields may be reported as messages too. Offsets are relative to the given byte slice.
+func ParseProto(b []byte) ([]*ProtoNode, error) {
+	return parseProto(b, 0, 0)
+}
+
+// parseProto returns the decoded fields of the given byte slice from the given offset and depth.
+func parseProto(b []byte, i, depth int) ([]*ProtoNode, error) {
+	if depth > maxProtoDepth {
+		return nil, fmt.Errorf("byteman: protobuf nesting exceeds %d levels", maxProtoDepth)
+	}
+	var nodes []*ProtoNode
+	for i < len(b) {
+		f, err := readProtoField(b, i, depth)
+		if err != nil {
+			return nil, err
+		}
+		if f.Type == ProtoEndGroup {
+			return nil, &FrameError{Pos: f.Offset, Msg: fmt.Sprintf("unexpected end group %d", f.Number)}
+		}
+		node := &ProtoNode{ProtoField: f}
+		switch f.Type {
+		case ProtoStartGroup:
+			if node.Children, err = parseProto(b[:f.ValueOffset+len(f.Bytes)], f.ValueOffset, depth+1); err != nil {
+				return nil, err
+			}
+		case ProtoBytes:
+			if len(f.Bytes) > 0 {
+				// Not a message if it does not parse.
+				node.Children, _ = parseProto(b[:f.End], f.ValueOffset, depth+1)
+			}
+		}
+		nodes = append(nodes, node)
+		i = f.End
+	}
+	return nodes, nil
+}
+
+// DumpProto returns the text dump of the given decoded message tree.
+// Every line contains the field number, wire type, byte offset and value, indented by the nesting depth.
+func DumpProto(nodes []*ProtoNode) string {
+	var sb strings.Builder
+	dumpProto(&sb, nodes, 0)
+	return sb.String()
+}
+
+// dumpProto writes the text dump of the given nodes by the given depth.
+func dumpProto(sb *strings.Builder, nodes []*ProtoNode, depth int) {
+	for _, n := range nodes {
+		sb.WriteString(strings.Repeat("  ", depth))
+		fmt.Fprintf(sb, "%d %s @%d:", n.Number, n.Type, n.Offset)
+		switch n.Type {
+		case ProtoVarint:
+			fmt.Fprintf(sb, " %d", n.Value)
+			if s := DecodeZigZag(n.Value); s < 0 {
+				fmt.Fprintf(sb, " (sint %d)", s)
+			}
+		case ProtoFixed32:
+			fmt.Fprintf(sb, " %#08x", n.Value)
+		case ProtoFixed64:
+			fmt.Fprintf(sb, " %#016x", n.Value)
+		case ProtoBytes:
+			if n.Children == nil || isPrintable(n.Bytes) {
+				fmt.Fprintf(sb, " %s", formatProtoBytes(n.Bytes))
+			} else {
+				fmt.Fprintf(sb, " (%d bytes)", len(n.Bytes))
+			}
+		}
+		sb.WriteByte('\n')
+		if n.Type == ProtoStartGroup || !isPrintable(n.Bytes) {
+			dumpProto(sb, n.Children, depth+1)
+		}
+	}
+}
+
+// formatProtoBytes returns the quoted string of the given printable bytes, otherwise the hex string.
+func formatProtoBytes(b []byte) string {
+	if isPrintable(b) {
+		return strconv.Quote(string(b))
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// isPrintable returns whether the given byte slice is printable UTF-8 text.
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+// protoMessage represents a message of a varint (150), a string ("testing"), an embedded message,
+// a fixed32, a fixed64, a ZigZag encoded varint (-2) and a group.
+var protoMessage = []byte{
+	0x08, 0x96, 0x01,
+	0x12, 0x07, 0x74, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x67,
+	0x1a, 0x03, 0x08, 0x96, 0x01,
+	0x25, 0x78, 0x56, 0x34, 0x12,
+	0x29, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01,
+	0x30, 0x03,
+	0x3b, 0x08, 0x01, 0x3c,
+}
+
+func TestZigZag(t *testing.T) {
+	table := []struct {
+		arg0 int64
+		out0 uint64
+	}{
+		{0, 0},
+		{-1, 1},
+		{1, 2},
+		{-2, 3},
+		{2147483647, 4294967294},
+		{-2147483648, 4294967295},
+		{-9223372036854775808, 18446744073709551615},
+	}
+	for _, v := range table {
+		if out0 := byteman.EncodeZigZag(v.arg0); out0 != v.out0 {
+			t.Errorf("got %v, want %v", out0, v.out0)
+		}
+		if out0 := byteman.DecodeZigZag(v.out0); out0 != v.arg0 {
+			t.Errorf("got %v, want %v", out0, v.arg0)
+		}
+	}
+}
+
+func TestReadProtoTag(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out0 uint32
+		out1 byteman.ProtoWireType
+		out2 int
+		out3 bool
+	}{
+		{[]byte{0x08}, 1, byteman.ProtoVarint, 1, false},
+		{[]byte{0x12}, 2, byteman.ProtoBytes, 1, false},
+		{[]byte{0xf8, 0xff, 0xff, 0xff, 0x0f}, 536870911, byteman.ProtoVarint, 5, false},
+		{[]byte{0x00}, 0, 0, 0, true},
+		{[]byte{0x0e}, 0, 0, 0, true},
+		{[]byte{0x80}, 0, 0, 0, true},
+	}
+	for _, v := range table {
+		out0, out1, out2, err := byteman.ReadProtoTag(v.arg0)
+		if out0 != v.out0 || out1 != v.out1 || out2 != v.out2 || (err != nil) != v.out3 {
+			t.Errorf("got %v %v %v %v, want %v %v %v %v", out0, out1, out2, err, v.out0, v.out1, v.out2, v.out3)
+		}
+	}
+}
+
+func TestProtoReader(t *testing.T) {
+	table := []struct {
+		out0 uint32
+		out1 byteman.ProtoWireType
+		out2 uint64
+		out3 []byte
+		out4 int
+		out5 int
+	}{
+		{1, byteman.ProtoVarint, 150, nil, 0, 1},
+		{2, byteman.ProtoBytes, 0, []byte("testing"), 3, 5},
+		{3, byteman.ProtoBytes, 0, []byte{0x08, 0x96, 0x01}, 12, 14},
+		{4, byteman.ProtoFixed32, 0x12345678, nil, 17, 18},
+		{5, byteman.ProtoFixed64, 0x0102030405060708, nil, 22, 23},
+		{6, byteman.ProtoVarint, 3, nil, 31, 32},
+		{7, byteman.ProtoStartGroup, 0, []byte{0x08, 0x01}, 33, 34},
+	}
+	r := byteman.NewProtoReader(protoMessage)
+	for _, v := range table {
+		f, err := r.Next()
+		if err != nil {
+			t.Fatalf("got %v, want nil", err)
+		}
+		if f.Number != v.out0 || f.Type != v.out1 || f.Value != v.out2 || !bytes.Equal(f.Bytes, v.out3) || f.Offset != v.out4 || f.ValueOffset != v.out5 {
+			t.Errorf("got %+v, want %v %v %v %v %v %v", f, v.out0, v.out1, v.out2, v.out3, v.out4, v.out5)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("got %v, want %v", err, io.EOF)
+	}
+}
+
+func TestProtoReaderError(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out0 error
+	}{
+		{[]byte{0x08}, io.ErrUnexpectedEOF},
+		{[]byte{0x25, 0x01, 0x02}, io.ErrUnexpectedEOF},
+		{[]byte{0x12, 0x05, 0x01}, &byteman.LengthError{}},
+		{[]byte{0x0c}, &byteman.FrameError{}},
+		{[]byte{0x0b, 0x14}, &byteman.FrameError{}},
+		{[]byte{0x0b, 0x08, 0x01}, io.ErrUnexpectedEOF},
+	}
+	for _, v := range table {
+		r := byteman.NewProtoReader(v.arg0)
+		_, err := r.Next()
+		switch v.out0.(type) {
+		case *byteman.LengthError:
+			var e *byteman.LengthError
+			if !errors.As(err, &e) {
+				t.Errorf("got %v, want *LengthError for %x", err, v.arg0)
+			}
+		case *byteman.FrameError:
+			var e *byteman.FrameError
+			if !errors.As(err, &e) {
+				t.Errorf("got %v, want *FrameError for %x", err, v.arg0)
+			}
+		default:
+			if err != v.out0 {
+				t.Errorf("got %v, want %v for %x", err, v.out0, v.arg0)
+			}
+		}
+	}
+}
+
+func TestProtoBuilder(t *testing.T) {
+	out0, err := byteman.NewProtoBuilder().
+		AddVarint(1, 150).
+		AddString(2, "testing").
+		Begin(3).AddVarint(1, 150).End().
+		AddFixed32(4, 0x12345678).
+		AddFixed64(5, 0x0102030405060708).
+		AddSint(6, -2).
+		StartGroup(7).AddVarint(1, 1).EndGroup(7).
+		Bytes()
+	if err != nil || !bytes.Equal(out0, protoMessage) {
+		t.Errorf("got %x %v, want %x", out0, err, protoMessage)
+	}
+
+	if out0, err := byteman.NewProtoBuilder().AddInt(1, -1).Bytes(); err != nil || len(out0) != 11 {
+		t.Errorf("got %x %v, want 11 bytes", out0, err)
+	}
+	if _, err := byteman.NewProtoBuilder().AddVarint(0, 1).Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.NewProtoBuilder().Begin(1).Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.NewProtoBuilder().End().Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func TestParseProto(t *testing.T) {
+	nodes, err := byteman.ParseProto(protoMessage)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if len(nodes) != 7 {
+		t.Fatalf("got %v, want 7 nodes", len(nodes))
+	}
+	if c := nodes[2].Children; len(c) != 1 || c[0].Number != 1 || c[0].Value != 150 || c[0].Offset != 14 {
+		t.Errorf("got %+v, want field 1 (150) at 14", c)
+	}
+	if c := nodes[6].Children; len(c) != 1 || c[0].Number != 1 || c[0].Value != 1 || c[0].Offset != 34 {
+		t.Errorf("got %+v, want field 1 (1) at 34", c)
+	}
+
+	want := "1 varint @0: 150\n" +
+		"2 bytes @3: \"testing\"\n" +
+		"3 bytes @12: (3 bytes)\n" +
+		"  1 varint @14: 150\n" +
+		"4 fixed32 @17: 0x12345678\n" +
+		"5 fixed64 @22: 0x0102030405060708\n" +
+		"6 varint @31: 3 (sint -2)\n" +
+		"7 group @33:\n" +
+		"  1 varint @34: 1 (sint -1)\n"
+	if out0 := byteman.DumpProto(nodes); out0 != want {
+		t.Errorf("got %q, want %q", out0, want)
+	}
+
+	if _, err := byteman.ParseProto([]byte{0x0c}); err == nil {
+		t.Error("got nil, want error")
+	}
+}
+
+func BenchmarkParseProto(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.ParseProto(protoMessage)
+	}
+}
@@ -0,0 +1,321 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// EncodePackBits returns the Apple PackBits (TIFF) encoding of the given byte slice.
+// Runs of three or more equal bytes are encoded as repeat packets, other bytes as literal packets.
+func EncodePackBits(b []byte) []byte {
+	out := make([]byte, 0, len(b)+len(b)/128+1)
+	for i := 0; i < len(b); {
+		run := 1
+		for i+run < len(b) && b[i+run] == b[i] && run < 128 {
+			run++
+		}
+		if run >= 3 {
+			out = append(out, byte(1-run), b[i])
+			i += run
+			continue
+		}
+		start := i
+		for i < len(b) && i-start < 128 {
+			if i+2 < len(b) && b[i] == b[i+1] && b[i] == b[i+2] {
+				break
+			}
+			i++
+		}
+		out = append(out, byte(i-start-1))
+		out = append(out, b[start:i]...)
+	}
+	return out
+}
+
+// DecodePackBits returns the decoded byte slice by the given Apple PackBits (TIFF) encoded byte slice
+// and maximum output size (zero or negative means no limit).
+// It returns io.ErrUnexpectedEOF for truncated packets and *LengthError if the output exceeds the maximum size.
+func DecodePackBits(b []byte, max int) ([]byte, error) {
+	out := make([]byte, 0, len(b)*2)
+	for i := 0; i < len(b); {
+		h := int(int8(b[i]))
+		i++
+		switch {
+		case h >= 0:
+			n := h + 1
+			if i+n > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if err := checkOutput(len(out), n, max); err != nil {
+				return nil, err
+			}
+			out = append(out, b[i:i+n]...)
+			i += n
+		case h == -128:
+			// No operation.
+		default:
+			if i >= len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			n := 1 - h
+			if err := checkOutput(len(out), n, max); err != nil {
+				return nil, err
+			}
+			out = appendRun(out, b[i], n)
+			i++
+		}
+	}
+	return out, nil
+}
+
+// EncodeRLE returns the run-length encoding of the given byte slice as (count, value) byte pairs.
+// The count is between 1 and 255.
+func EncodeRLE(b []byte) []byte {
+	out := make([]byte, 0, len(b)/2+2)
+	for i := 0; i < len(b); {
+		run := 1
+		for i+run < len(b) && b[i+run] == b[i] && run < 255 {
+			run++
+		}
+		out = append(out, byte(run), b[i])
+		i += run
+	}
+	return out
+}
+
+// DecodeRLE returns the decoded byte slice by the given (count, value) run-length encoded byte slice
+// and maximum output size (zero or negative means no limit).
+// It returns io.ErrUnexpectedEOF for a truncated pair, *FrameError for a zero count and
+// *LengthError if the output exceeds the maximum size.
+func DecodeRLE(b []byte, max int) ([]byte, error) {
+	if len(b)%2 != 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i += 2 {
+		n := int(b[i])
+		if n == 0 {
+			return nil, &FrameError{Pos: i, Msg: "zero run count"}
+		}
+		if err := checkOutput(len(out), n, max); err != nil {
+			return nil, err
+		}
+		out = appendRun(out, b[i+1], n)
+	}
+	return out, nil
+}
+
+// EncodeRLE8 returns the BMP RLE8 encoding of the given 8 bit pixels by the given image width.
+// Every row is terminated by an end of line marker and the bitmap by an end of bitmap marker.
+func EncodeRLE8(pixels []byte, width int) ([]byte, error) {
+	if err := checkImage(len(pixels), width); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(pixels)+len(pixels)/width*2+2)
+	for y := 0; y < len(pixels); y += width {
+		out = appendRLERow(out, pixels[y:y+width], false)
+		out = append(out, 0, 0)
+	}
+	return append(out, 0, 1), nil
+}
+
+// DecodeRLE8 returns the 8 bit pixels by the given BMP RLE8 encoded byte slice, image width and height.
+// The maximum output size (zero or negative means no limit) is checked against width*height before decoding.
+// Rows are returned in the stored order. Skipped pixels (delta and end of line markers) are zero.
+// It returns io.ErrUnexpectedEOF for truncated data, *FrameError for pixels which exceed the image bounds and
+// *LengthError if the image size exceeds the maximum size.
+func DecodeRLE8(b []byte, width, height, max int) ([]byte, error) {
+	return decodeBMPRLE(b, width, height, max, false)
+}
+
+// EncodeRLE4 returns the BMP RLE4 encoding of the given 4 bit pixels (one pixel per byte) by the given image width.
+// Every row is terminated by an end of line marker and the bitmap by an end of bitmap marker.
+func EncodeRLE4(pixels []byte, width int) ([]byte, error) {
+	if err := checkImage(len(pixels), width); err != nil {
+		return nil, err
+	}
+	for i, p := range pixels {
+		if p > 0x0f {
+			return nil, fmt.Errorf("byteman: pixel %d value %#02x overflows 4 bits", i, p)
+		}
+	}
+	out := make([]byte, 0, len(pixels)/2+len(pixels)/width*2+2)
+	for y := 0; y < len(pixels); y += width {
+		out = appendRLERow(out, pixels[y:y+width], true)
+		out = append(out, 0, 0)
+	}
+	return append(out, 0, 1), nil
+}
+
+// DecodeRLE4 returns the 4 bit pixels (one pixel per byte) by the given BMP RLE4 encoded byte slice, image width and height.
+// The maximum output size (zero or negative means no limit) is checked against width*height before decoding.
+// Rows are returned in the stored order. Skipped pixels (delta and end of line markers) are zero.
+// It returns io.ErrUnexpectedEOF for truncated data, *FrameError for pixels which exceed the image bounds and
+// *LengthError if the image size exceeds the maximum size.
+func DecodeRLE4(b []byte, width, height, max int) ([]byte, error) {
+	return decodeBMPRLE(b, width, height, max, true)
+}
+
+// appendRLERow appends the BMP RLE8 or RLE4 encoding of the given row.
+func appendRLERow(out, row []byte, nibbles bool) []byte {
+	for x := 0; x < len(row); {
+		run := 1
+		for x+run < len(row) && row[x+run] == row[x] && run < 255 {
+			run++
+		}
+		if run >= 2 {
+			v := row[x]
+			if nibbles {
+				v = v<<4 | v
+			}
+			out = append(out, byte(run), v)
+			x += run
+			continue
+		}
+		end := x
+		for end < len(row) && end-x < 255 && !(end+1 < len(row) && row[end] == row[end+1]) {
+			end++
+		}
+		lit := row[x:end]
+		if len(lit) < 3 {
+			// The absolute mode requires at least 3 pixels.
+			for _, v := range lit {
+				if nibbles {
+					v <<= 4
+				}
+				out = append(out, 1, v)
+			}
+		} else {
+			out = append(out, 0, byte(len(lit)))
+			n := len(lit)
+			if nibbles {
+				for i := 0; i < len(lit); i += 2 {
+					v := lit[i] << 4
+					if i+1 < len(lit) {
+						v |= lit[i+1]
+					}
+					out = append(out, v)
+				}
+				n = (n + 1) / 2
+			} else {
+				out = append(out, lit...)
+			}
+			if n%2 != 0 {
+				out = append(out, 0)
+			}
+		}
+		x = end
+	}
+	return out
+}
+
+// decodeBMPRLE returns the pixels by the given BMP RLE8 or RLE4 encoded byte slice, image width, height and
+// maximum output size.
+func decodeBMPRLE(b []byte, width, height, max int, nibbles bool) ([]byte, error) {
+	if width <= 0 || height <= 0 || height > math.MaxInt/width {
+		return nil, fmt.Errorf("byteman: invalid image size %dx%d", width, height)
+	}
+	if err := checkOutput(0, width*height, max); err != nil {
+		return nil, err
+	}
+	out := make([]byte, width*height)
+	x, y := 0, 0
+	for i := 0; i < len(b); {
+		if i+2 > len(b) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		c, v := int(b[i]), b[i+1]
+		pos := i
+		i += 2
+		if c > 0 {
+			if y >= height || x+c > width {
+				return nil, &FrameError{Pos: pos, Msg: "run exceeds the image bounds"}
+			}
+			for j := 0; j < c; j++ {
+				p := v
+				if nibbles {
+					if j%2 == 0 {
+						p = v >> 4
+					} else {
+						p = v & 0x0f
+					}
+				}
+				out[y*width+x+j] = p
+			}
+			x += c
+			continue
+		}
+		switch v {
+		case 0:
+			x, y = 0, y+1
+		case 1:
+			return out, nil
+		case 2:
+			if i+2 > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			x, y = x+int(b[i]), y+int(b[i+1])
+			i += 2
+			if x > width || y > height {
+				return nil, &FrameError{Pos: pos, Msg: "delta exceeds the image bounds"}
+			}
+		default:
+			c = int(v)
+			n := c
+			if nibbles {
+				n = (c + 1) / 2
+			}
+			if i+n > len(b) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if y >= height || x+c > width {
+				return nil, &FrameError{Pos: pos, Msg: "absolute run exceeds the image bounds"}
+			}
+			for j := 0; j < c; j++ {
+				switch {
+				case !nibbles:
+					out[y*width+x+j] = b[i+j]
+				case j%2 == 0:
+					out[y*width+x+j] = b[i+j/2] >> 4
+				default:
+					out[y*width+x+j] = b[i+j/2] & 0x0f
+				}
+			}
+			x += c
+			i += n + n%2
+			if i > len(b) {
+				// The padding byte of the last absolute run is optional.
+				i = len(b)
+			}
+		}
+	}
+	return out, nil
+}
+
+// checkImage returns an error if the given number of pixels is not a multiple of the given image width.
+func checkImage(n, width int) error {
+	if width <= 0 || n%width != 0 {
+		return fmt.Errorf("byteman: %d pixels is not a multiple of image width %d", n, width)
+	}
+	return nil
+}
+
+// checkOutput returns a *LengthError if appending n bytes to the given output size exceeds the maximum size.
+func checkOutput(size, n, max int) error {
+	if max > 0 && size+n > max {
+		return &LengthError{Declared: uint64(size + n), Available: max, Max: max}
+	}
+	return nil
+}
+
+// appendRun appends the given byte n times.
+func appendRun(b []byte, v byte, n int) []byte {
+	for i := 0; i < n; i++ {
+		b = append(b, v)
+	}
+	return b
+}
@@ -0,0 +1,171 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestPackBits(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		out  []byte
+	}{
+		{
+			[]byte{0xaa, 0xaa, 0xaa, 0x80, 0x00, 0x2a, 0xaa, 0xaa, 0xaa, 0xaa, 0x80, 0x00, 0x2a, 0x22, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa},
+			[]byte{0xfe, 0xaa, 0x02, 0x80, 0x00, 0x2a, 0xfd, 0xaa, 0x03, 0x80, 0x00, 0x2a, 0x22, 0xf7, 0xaa},
+		},
+		{bytes.Repeat([]byte{0x01}, 130), []byte{0x81, 0x01, 0x01, 0x01, 0x01}},
+		{[]byte{0x01, 0x01}, []byte{0x01, 0x01, 0x01}},
+		{[]byte{}, []byte{}},
+	}
+	for _, v := range table {
+		if b := byteman.EncodePackBits(v.arg0); !bytes.Equal(b, v.out) {
+			t.Errorf("got %#v, want %#v", b, v.out)
+		}
+		if b, err := byteman.DecodePackBits(v.out, 0); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.arg0) {
+			t.Errorf("got %#v, want %#v", b, v.arg0)
+		}
+	}
+
+	long := append(bytes.Repeat([]byte{0x01, 0x02}, 100), 0x03)
+	if b, err := byteman.DecodePackBits(byteman.EncodePackBits(long), len(long)); err != nil || !bytes.Equal(b, long) {
+		t.Errorf("got %v, %v, want %v", b, err, long)
+	}
+	if b, err := byteman.DecodePackBits([]byte{0x80, 0x00, 0x05}, 0); err != nil || !bytes.Equal(b, []byte{0x05}) {
+		t.Errorf("got %v, %v, want %v", b, err, []byte{0x05})
+	}
+	if _, err := byteman.DecodePackBits([]byte{0x02, 0x01}, 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := byteman.DecodePackBits([]byte{0x81, 0x00, 0x81, 0x00}, 200); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.LengthError); !ok || e.Declared != 256 {
+		t.Errorf("got %v, want LengthError", err)
+	}
+}
+
+func BenchmarkEncodePackBits(b *testing.B) {
+	data := bytes.Repeat([]byte{0x01, 0x02, 0x02, 0x02, 0x03}, 100)
+	for i := 0; i < b.N; i++ {
+		byteman.EncodePackBits(data)
+	}
+}
+
+func TestRLE(t *testing.T) {
+	data := byteman.Combine(bytes.Repeat([]byte{0x07}, 300), []byte{0x01, 0x02, 0x02})
+	want := []byte{0xff, 0x07, 0x2d, 0x07, 0x01, 0x01, 0x02, 0x02}
+	if b := byteman.EncodeRLE(data); !bytes.Equal(b, want) {
+		t.Errorf("got %#v, want %#v", b, want)
+	}
+	if b, err := byteman.DecodeRLE(want, 0); err != nil || !bytes.Equal(b, data) {
+		t.Errorf("got %v, %v, want %v", b, err, data)
+	}
+
+	if _, err := byteman.DecodeRLE(want, 300); err == nil {
+		t.Error("got nil, want error")
+	} else if _, ok := err.(*byteman.LengthError); !ok {
+		t.Errorf("got %v, want LengthError", err)
+	}
+	if _, err := byteman.DecodeRLE([]byte{0x01}, 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := byteman.DecodeRLE([]byte{0x01, 0x01, 0x00, 0x01}, 0); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.FrameError); !ok || e.Pos != 2 {
+		t.Errorf("got %v, want position 2", err)
+	}
+}
+
+func TestRLE8(t *testing.T) {
+	// The example from the BMP specification.
+	encoded := []byte{0x03, 0x04, 0x05, 0x06, 0x00, 0x03, 0x45, 0x56, 0x67, 0x00, 0x02, 0x78, 0x00, 0x02, 0x05, 0x01, 0x01, 0x78, 0x00, 0x00, 0x09, 0x1e, 0x00, 0x01}
+	want := make([]byte, 60)
+	copy(want, []byte{0x04, 0x04, 0x04, 0x06, 0x06, 0x06, 0x06, 0x06, 0x45, 0x56, 0x67, 0x78, 0x78})
+	want[20+18] = 0x78
+	copy(want[40:], bytes.Repeat([]byte{0x1e}, 9))
+	if b, err := byteman.DecodeRLE8(encoded, 20, 3, 0); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if !bytes.Equal(b, want) {
+		t.Errorf("got %v, want %v", b, want)
+	}
+
+	pixels := []byte{
+		0x01, 0x01, 0x01, 0x02, 0x03, 0x04, 0x05,
+		0x09, 0x08, 0x08, 0x08, 0x08, 0x08, 0x07,
+	}
+	enc, err := byteman.EncodeRLE8(pixels, 7)
+	wantEnc := []byte{0x03, 0x01, 0x00, 0x04, 0x02, 0x03, 0x04, 0x05, 0x00, 0x00, 0x01, 0x09, 0x05, 0x08, 0x01, 0x07, 0x00, 0x00, 0x00, 0x01}
+	if err != nil || !bytes.Equal(enc, wantEnc) {
+		t.Errorf("got %#v, %v, want %#v", enc, err, wantEnc)
+	}
+	if b, err := byteman.DecodeRLE8(enc, 7, 2, 0); err != nil || !bytes.Equal(b, pixels) {
+		t.Errorf("got %v, %v, want %v", b, err, pixels)
+	}
+
+	if _, err := byteman.EncodeRLE8(pixels, 5); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.DecodeRLE8([]byte{0x08, 0x01}, 7, 2, 0); err == nil {
+		t.Error("got nil, want error")
+	} else if _, ok := err.(*byteman.FrameError); !ok {
+		t.Errorf("got %v, want FrameError", err)
+	}
+	if _, err := byteman.DecodeRLE8([]byte{0x00, 0x05, 0x01}, 7, 2, 0); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := byteman.DecodeRLE8([]byte{0x02, 0x41, 0x00, 0x01}, math.MaxInt, 2, 0); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.DecodeRLE8([]byte{0x02, 0x41, 0x00, 0x01}, 7, 2, 13); err == nil {
+		t.Error("got nil, want error")
+	} else if _, ok := err.(*byteman.LengthError); !ok {
+		t.Errorf("got %v, want LengthError", err)
+	}
+	if b, err := byteman.DecodeRLE8([]byte{0x02, 0x41, 0x00, 0x01}, 7, 2, 14); err != nil || len(b) != 14 {
+		t.Errorf("got %v, %v, want 14 bytes", b, err)
+	}
+}
+
+func BenchmarkDecodeRLE8(b *testing.B) {
+	pixels := bytes.Repeat([]byte{0x01, 0x01, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, 80)
+	enc, _ := byteman.EncodeRLE8(pixels, 64)
+	for i := 0; i < b.N; i++ {
+		byteman.DecodeRLE8(enc, 64, 10, 0)
+	}
+}
+
+func TestRLE4(t *testing.T) {
+	// The example from the BMP specification.
+	encoded := []byte{0x03, 0x04, 0x05, 0x06, 0x00, 0x06, 0x45, 0x56, 0x67, 0x00, 0x04, 0x78, 0x00, 0x02, 0x05, 0x01, 0x04, 0x78, 0x00, 0x00, 0x09, 0x1e, 0x00, 0x01}
+	want := make([]byte, 84)
+	copy(want, []byte{0x0, 0x4, 0x0, 0x0, 0x6, 0x0, 0x6, 0x0, 0x4, 0x5, 0x5, 0x6, 0x6, 0x7, 0x7, 0x8, 0x7, 0x8})
+	copy(want[28+23:], []byte{0x7, 0x8, 0x7, 0x8})
+	copy(want[56:], []byte{0x1, 0xe, 0x1, 0xe, 0x1, 0xe, 0x1, 0xe, 0x1})
+	if b, err := byteman.DecodeRLE4(encoded, 28, 3, 0); err != nil {
+		t.Errorf("got %v, want nil", err)
+	} else if !bytes.Equal(b, want) {
+		t.Errorf("got %v, want %v", b, want)
+	}
+
+	pixels := []byte{0x1, 0x1, 0x1, 0x2, 0x3, 0x4, 0x5, 0xf, 0xf}
+	enc, err := byteman.EncodeRLE4(pixels, 9)
+	wantEnc := []byte{0x03, 0x11, 0x00, 0x04, 0x23, 0x45, 0x02, 0xff, 0x00, 0x00, 0x00, 0x01}
+	if err != nil || !bytes.Equal(enc, wantEnc) {
+		t.Errorf("got %#v, %v, want %#v", enc, err, wantEnc)
+	}
+	if b, err := byteman.DecodeRLE4(enc, 9, 1, 0); err != nil || !bytes.Equal(b, pixels) {
+		t.Errorf("got %v, %v, want %v", b, err, pixels)
+	}
+	if _, err := byteman.EncodeRLE4([]byte{0x10}, 1); err == nil {
+		t.Error("got nil, want error")
+	}
+}
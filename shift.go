@@ -0,0 +1,136 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+// ShiftLeft returns the given byte slice logically shifted left by the given number of bits.
+// The byte slice is treated as one integer by the given byte order (endianness) and the length is preserved.
+// A negative number of bits shifts right.
+func ShiftLeft(b []byte, n int, bo ByteOrder) []byte {
+	return shift(b, n, false, bo)
+}
+
+// ShiftRight returns the given byte slice logically shifted right by the given number of bits.
+// The byte slice is treated as one integer by the given byte order (endianness) and the length is preserved.
+// A negative number of bits shifts left.
+func ShiftRight(b []byte, n int, bo ByteOrder) []byte {
+	return shift(b, -n, false, bo)
+}
+
+// ShiftRightArithmetic returns the given byte slice arithmetically shifted right (sign extended) by the given
+// number of bits. The byte slice is treated as one two's complement integer by the given byte order (endianness).
+// A negative number of bits shifts left.
+func ShiftRightArithmetic(b []byte, n int, bo ByteOrder) []byte {
+	return shift(b, -n, true, bo)
+}
+
+// RotateLeft returns the given byte slice rotated left by the given number of bits.
+// The byte slice is treated as one integer by the given byte order (endianness).
+// A negative number of bits rotates right.
+func RotateLeft(b []byte, n int, bo ByteOrder) []byte {
+	bits := len(b) * 8
+	if bits == 0 {
+		return []byte{}
+	}
+	if n = n % bits; n < 0 {
+		n += bits
+	}
+	return OrInPlace(ShiftLeft(b, n, bo), ShiftRight(b, bits-n, bo))
+}
+
+// RotateRight returns the given byte slice rotated right by the given number of bits.
+// The byte slice is treated as one integer by the given byte order (endianness).
+// A negative number of bits rotates left.
+func RotateRight(b []byte, n int, bo ByteOrder) []byte {
+	if len(b) == 0 {
+		return []byte{}
+	}
+	return RotateLeft(b, -(n % (len(b) * 8)), bo)
+}
+
+// RotateBytesLeft returns the given byte slice with its bytes rotated left by the given number of bytes
+// (i.e. the byte at index n becomes the first byte). A negative number of bytes rotates right.
+func RotateBytesLeft(b []byte, n int) []byte {
+	nb := make([]byte, len(b))
+	if len(b) == 0 {
+		return nb
+	}
+	if n = n % len(b); n < 0 {
+		n += len(b)
+	}
+	copy(nb, b[n:])
+	copy(nb[len(b)-n:], b[:n])
+	return nb
+}
+
+// RotateBytesRight returns the given byte slice with its bytes rotated right by the given number of bytes
+// (i.e. the first byte moves to index n). A negative number of bytes rotates left.
+func RotateBytesRight(b []byte, n int) []byte {
+	if len(b) == 0 {
+		return []byte{}
+	}
+	return RotateBytesLeft(b, -(n % len(b)))
+}
+
+// shift returns the given byte slice shifted by the given number of bits.
+// Positive numbers shift left and negative numbers shift right.
+func shift(b []byte, n int, arithmetic bool, bo ByteOrder) []byte {
+	bits := len(b) * 8
+	if n > bits {
+		n = bits + 8
+	} else if n < -bits {
+		n = -bits - 8
+	}
+	left := n >= 0
+	if !left {
+		n = -n
+	}
+
+	le := bo.Type() == ByteOrderTypeLittleEndian
+	var fill byte
+	if arithmetic && len(b) > 0 {
+		msb := b[0]
+		if le {
+			msb = b[len(b)-1]
+		}
+		if msb&0x80 != 0 {
+			fill = 0xff
+		}
+	}
+	// get returns the byte at the given big-endian index, or the fill byte if it is out of range.
+	get := func(j int) byte {
+		if j < 0 || j >= len(b) {
+			if left {
+				return 0
+			}
+			return fill
+		}
+		if le {
+			return b[len(b)-1-j]
+		}
+		return b[j]
+	}
+
+	q, r := n/8, uint(n%8)
+	nb := make([]byte, len(b))
+	for i := range nb {
+		var c byte
+		if left {
+			c = get(i+q) << r
+			if r > 0 {
+				c |= get(i+q+1) >> (8 - r)
+			}
+		} else {
+			c = get(i-q) >> r
+			if r > 0 {
+				c |= get(i-q-1) << (8 - r)
+			}
+		}
+		if le {
+			nb[len(nb)-1-i] = c
+		} else {
+			nb[i] = c
+		}
+	}
+	return nb
+}
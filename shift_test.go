@@ -0,0 +1,100 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"math/bits"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestShift(t *testing.T) {
+	table := []struct {
+		fn   func(b []byte, n int, bo byteman.ByteOrder) []byte
+		arg0 []byte
+		arg1 int
+		arg2 byteman.ByteOrder
+		out  []byte
+	}{
+		{byteman.ShiftLeft, []byte{0x81, 0x01}, 1, &byteman.BigEndian{}, []byte{0x02, 0x02}},
+		{byteman.ShiftLeft, []byte{0x81, 0x01}, 1, &byteman.LittleEndian{}, []byte{0x02, 0x03}},
+		{byteman.ShiftLeft, []byte{0x12, 0x34, 0x56}, 12, &byteman.BigEndian{}, []byte{0x45, 0x60, 0x00}},
+		{byteman.ShiftLeft, []byte{0x12, 0x34}, 16, &byteman.BigEndian{}, []byte{0x00, 0x00}},
+		{byteman.ShiftLeft, []byte{0x12, 0x34}, -4, &byteman.BigEndian{}, []byte{0x01, 0x23}},
+		{byteman.ShiftRight, []byte{0x81, 0x01}, 1, &byteman.BigEndian{}, []byte{0x40, 0x80}},
+		{byteman.ShiftRight, []byte{0x81, 0x01}, 1, &byteman.LittleEndian{}, []byte{0xc0, 0x00}},
+		{byteman.ShiftRight, []byte{0x12, 0x34, 0x56}, 12, &byteman.BigEndian{}, []byte{0x00, 0x01, 0x23}},
+		{byteman.ShiftRight, []byte{0x12, 0x34}, 100, &byteman.BigEndian{}, []byte{0x00, 0x00}},
+		{byteman.ShiftRightArithmetic, []byte{0x81, 0x00}, 4, &byteman.BigEndian{}, []byte{0xf8, 0x10}},
+		{byteman.ShiftRightArithmetic, []byte{0x00, 0x81}, 4, &byteman.LittleEndian{}, []byte{0x10, 0xf8}},
+		{byteman.ShiftRightArithmetic, []byte{0x71, 0x00}, 4, &byteman.BigEndian{}, []byte{0x07, 0x10}},
+		{byteman.ShiftRightArithmetic, []byte{0x81, 0x00}, 100, &byteman.BigEndian{}, []byte{0xff, 0xff}},
+		{byteman.RotateLeft, []byte{0x81, 0x01}, 1, &byteman.BigEndian{}, []byte{0x02, 0x03}},
+		{byteman.RotateLeft, []byte{0x12, 0x34}, 20, &byteman.BigEndian{}, []byte{0x23, 0x41}},
+		{byteman.RotateLeft, []byte{0x12, 0x34}, -4, &byteman.BigEndian{}, []byte{0x41, 0x23}},
+		{byteman.RotateRight, []byte{0x81, 0x01}, 1, &byteman.BigEndian{}, []byte{0xc0, 0x80}},
+		{byteman.RotateRight, []byte{0x81, 0x01}, 1, &byteman.LittleEndian{}, []byte{0xc0, 0x80}},
+		{byteman.RotateRight, []byte{}, 1, &byteman.LittleEndian{}, []byte{}},
+		{byteman.ShiftLeft, []byte{}, 1, &byteman.LittleEndian{}, []byte{}},
+	}
+	for _, v := range table {
+		if b := v.fn(v.arg0, v.arg1, v.arg2); !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	// Compare with the native 64 bit operations.
+	x := uint64(0x8123456789abcdef)
+	for _, bo := range []byteman.ByteOrder{&byteman.BigEndian{}, &byteman.LittleEndian{}} {
+		b := byteman.FromUint(x, bo)
+		for n := 0; n <= 64; n++ {
+			if r := byteman.Uint64(byteman.ShiftLeft(b, n, bo), bo); r != x<<uint(n) {
+				t.Errorf("got %x, want %x", r, x<<uint(n))
+			}
+			if r := byteman.Uint64(byteman.ShiftRight(b, n, bo), bo); r != x>>uint(n) {
+				t.Errorf("got %x, want %x", r, x>>uint(n))
+			}
+			if r := byteman.Int64(byteman.ShiftRightArithmetic(b, n, bo), bo); r != int64(x)>>uint(n) {
+				t.Errorf("got %x, want %x", r, int64(x)>>uint(n))
+			}
+			if r := byteman.Uint64(byteman.RotateLeft(b, n, bo), bo); r != bits.RotateLeft64(x, n) {
+				t.Errorf("got %x, want %x", r, bits.RotateLeft64(x, n))
+			}
+			if r := byteman.Uint64(byteman.RotateRight(b, n, bo), bo); r != bits.RotateLeft64(x, -n) {
+				t.Errorf("got %x, want %x", r, bits.RotateLeft64(x, -n))
+			}
+		}
+	}
+}
+
+func BenchmarkShiftLeft(b *testing.B) {
+	data := bytes.Repeat([]byte{0xa5}, 64)
+	for i := 0; i < b.N; i++ {
+		byteman.ShiftLeft(data, 13, &byteman.BigEndian{})
+	}
+}
+
+func TestRotateBytes(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		out  []byte
+	}{
+		{[]byte{1, 2, 3, 4}, 1, []byte{2, 3, 4, 1}},
+		{[]byte{1, 2, 3, 4}, 5, []byte{2, 3, 4, 1}},
+		{[]byte{1, 2, 3, 4}, -1, []byte{4, 1, 2, 3}},
+		{[]byte{1, 2, 3, 4}, 0, []byte{1, 2, 3, 4}},
+		{[]byte{}, 3, []byte{}},
+	}
+	for _, v := range table {
+		if b := byteman.RotateBytesLeft(v.arg0, v.arg1); !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+		if b := byteman.RotateBytesRight(v.out, v.arg1); !bytes.Equal(b, v.arg0) {
+			t.Errorf("got %v, want %v", b, v.arg0)
+		}
+	}
+}
@@ -5,40 +5,390 @@ package byteman
 
 import (
 	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// HexPadding represents the padding side of odd-length hex inputs.
+type HexPadding uint8
+
+const (
+	// HexPaddingNone rejects odd-length hex inputs.
+	HexPaddingNone HexPadding = 0
+	// HexPaddingLeft pads odd-length hex inputs with a zero digit on the left (i.e. "abc" is 0x0a 0xbc).
+	HexPaddingLeft HexPadding = 1
+	// HexPaddingRight pads odd-length hex inputs with a zero digit on the right (i.e. "abc" is 0xab 0xc0).
+	HexPaddingRight HexPadding = 2
+)
+
+// HexOptions represents the hex decoding options.
+// The zero value is the strict mode which only accepts even-length hex digits.
+type HexOptions struct {
+	// Lenient enables Prefix, Whitespace, Array and the `:`, `-` and `,` separators.
+	Lenient bool
+	// Prefix accepts `0x` prefixes. Odd-length prefixed values are padded on the left (i.e. "0xA" is 0x0a).
+	Prefix bool
+	// Whitespace accepts whitespace characters.
+	Whitespace bool
+	// Separators is the set of accepted separator characters.
+	Separators string
+	// Array accepts C array syntax (i.e. `{0xDE, 0xAD}`). It implies Prefix, Whitespace and the `,` separator.
+	Array bool
+	// Padding is the padding side of odd-length hex inputs.
+	Padding HexPadding
+}
+
+// HexStyle represents the hex encoding style.
+type HexStyle uint8
+
+const (
+	// HexStylePlain represents plain hex digits (i.e. `dead`, `de:ad`, `0xdead`).
+	HexStylePlain HexStyle = 0
+	// HexStyleC represents the C array literal style (i.e. `{0xde, 0xad}`).
+	HexStyleC HexStyle = 1
+	// HexStyleGo represents the Go slice literal style (i.e. `[]byte{0xde, 0xad}`).
+	HexStyleGo HexStyle = 2
+	// HexStylePython represents the Python bytes literal style (i.e. `b"\xde\xad"`).
+	HexStylePython HexStyle = 3
+)
+
+// HexFormat represents the hex encoding options.
+// The zero value produces lowercase hex digits without separators.
+type HexFormat struct {
+	// Style is the encoding style. The literal styles ignore Prefix, Separator and Group.
+	Style HexStyle
+	// Uppercase enables uppercase hex letters.
+	Uppercase bool
+	// Prefix adds `0x` prefixes to each group.
+	Prefix bool
+	// Separator is the separator between groups.
+	Separator string
+	// Group is the number of bytes per group.
+	// Default is 1 if Separator is set, otherwise the whole byte slice is one group.
+	Group int
+}
+
+// BinaryAlign represents the alignment of bit strings whose length is not a multiple of 8.
+type BinaryAlign uint8
+
+const (
+	// BinaryAlignNone rejects bit strings whose length is not a multiple of 8.
+	BinaryAlignNone BinaryAlign = 0
+	// BinaryAlignLeft aligns bits to the most significant bit of the first byte (i.e. "101" is 0b10100000).
+	BinaryAlignLeft BinaryAlign = 1
+	// BinaryAlignRight aligns bits to the least significant bit of the last byte (i.e. "101" is 0b00000101).
+	BinaryAlignRight BinaryAlign = 2
+)
+
+// BinaryFormat represents the binary encoding options.
+// The zero value produces binary digits without separators.
+type BinaryFormat struct {
+	// Prefix adds a `0b` prefix to each group.
+	Prefix bool
+	// Separator is the separator between groups.
+	Separator string
+	// Group is the number of bits per group.
+	// Default is 8 if Separator is set, otherwise the whole byte slice is one group.
+	Group int
+}
+
 // FromString returns a byte slice by the given string and desired byte size.
 func FromString(str string, size int) []byte {
-	if size == 0 {
-		size = len(str)
-	} else if size < 0 {
-		if ns := len(str) + size; ns > 0 {
-			size = ns
-		} else {
-			size = 0
-		}
-	}
+	return FromStringWith(str, size, ResizeOptions{})
+}
+
+// FromStringWith returns a byte slice by the given string, desired byte size and resize options.
+func FromStringWith(str string, size int, opts ResizeOptions) []byte {
+	return ResizeWith([]byte(str), size, opts)
+}
+
+// FromStringRuneSafe returns a byte slice by the given string and desired byte size.
+// Unlike FromString, it truncates the string only at rune boundaries and zero-fills the remainder.
+func FromStringRuneSafe(str string, size int) []byte {
+	size = resolveSize(len(str), size)
+	b := make([]byte, size)
+	copy(b, str[:runeBoundary(str, size)])
+	return b
+}
+
+// FromStringGraphemeSafe returns a byte slice by the given string and desired byte size.
+// Unlike FromStringRuneSafe, it also avoids splitting combining marks, zero width joiner sequences,
+// emoji modifiers, regional indicator pairs and CRLF from their preceding characters.
+func FromStringGraphemeSafe(str string, size int) []byte {
+	size = resolveSize(len(str), size)
 	b := make([]byte, size)
-	copy(b, []byte(str))
+	copy(b, str[:graphemeBoundary(str, size)])
 	return b
 }
 
 // FromHex returns a byte slice by the given ASCII Hex code and desired byte size.
+// It returns nil for invalid hex codes. See DecodeHex for errors and lenient decoding.
 func FromHex(hexcode string, size int) []byte {
-	decoded, err := hex.DecodeString(hexcode)
+	b, err := DecodeHex(hexcode, size, HexOptions{})
 	if err != nil {
 		return nil
 	}
-	if size == 0 {
-		size = len(decoded)
-	} else if size < 0 {
-		if ns := len(decoded) + size; ns > 0 {
-			size = ns
-		} else {
-			size = 0
+	return b
+}
+
+// FromHexWith returns a byte slice by the given ASCII Hex code, desired byte size and resize options.
+// It returns nil for invalid hex codes.
+func FromHexWith(hexcode string, size int, opts ResizeOptions) []byte {
+	b, err := DecodeHex(hexcode, 0, HexOptions{})
+	if err != nil {
+		return nil
+	}
+	return ResizeWith(b, size, opts)
+}
+
+// DecodeHex returns a byte slice by the given ASCII Hex code, desired byte size and options.
+// The returned error is a *SyntaxError which points to the offending character position.
+func DecodeHex(hexcode string, size int, opts HexOptions) ([]byte, error) {
+	if opts.Lenient {
+		opts.Prefix, opts.Whitespace, opts.Array = true, true, true
+		opts.Separators += ":-,"
+	}
+	if opts.Array {
+		opts.Prefix, opts.Whitespace = true, true
+		opts.Separators += ","
+	}
+
+	str, first := hexcode, 0
+	if opts.Array {
+		trimmed := strings.TrimSpace(str)
+		if l := len(trimmed); l >= 2 && (trimmed[0] == '{' && trimmed[l-1] == '}' || trimmed[0] == '[' && trimmed[l-1] == ']') {
+			first = strings.IndexByte(str, trimmed[0]) + 1
+			str = str[:first+l-2]
 		}
 	}
-	b := make([]byte, size)
-	copy(b, []byte(decoded))
+
+	digits := make([]byte, 0, len(str))
+	sep := true
+	for i := first; i < len(str); {
+		c := str[i]
+		switch {
+		case opts.Whitespace && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			sep = true
+			i++
+		case opts.Separators != "" && strings.IndexByte(opts.Separators, c) >= 0:
+			sep = true
+			i++
+		case opts.Prefix && sep && c == '0' && i+1 < len(str) && (str[i+1] == 'x' || str[i+1] == 'X'):
+			j := i + 2
+			for j < len(str) && isHexDigit(str[j]) {
+				j++
+			}
+			if j == i+2 {
+				return nil, &SyntaxError{Pos: j, Msg: "missing hex digits after prefix"}
+			}
+			if (j-i)%2 != 0 {
+				digits = append(digits, '0')
+			}
+			digits = append(digits, str[i+2:j]...)
+			sep = false
+			i = j
+		case isHexDigit(c):
+			digits = append(digits, c)
+			sep = false
+			i++
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("invalid hex character %q", c)}
+		}
+	}
+	if len(digits)%2 != 0 {
+		switch opts.Padding {
+		case HexPaddingLeft:
+			digits = append([]byte{'0'}, digits...)
+		case HexPaddingRight:
+			digits = append(digits, '0')
+		default:
+			return nil, &SyntaxError{Pos: len(hexcode), Msg: "odd length hex code"}
+		}
+	}
+
+	decoded := make([]byte, len(digits)/2)
+	hex.Decode(decoded, digits)
+	return Resize(decoded, size), nil
+}
+
+// ToHex returns the ASCII Hex code of the given byte slice by the given desired byte size and format.
+// The size argument has the same semantics as the FromHex size argument.
+func ToHex(b []byte, size int, f HexFormat) string {
+	b = Resize(b, size)
+	digits := hexLower
+	if f.Uppercase {
+		digits = hexUpper
+	}
+
+	var sb strings.Builder
+	switch f.Style {
+	case HexStyleC, HexStyleGo:
+		if f.Style == HexStyleGo {
+			sb.WriteString("[]byte")
+		}
+		sb.WriteByte('{')
+		for i, c := range b {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.Write([]byte{'0', 'x', digits[c>>4], digits[c&0x0f]})
+		}
+		sb.WriteByte('}')
+	case HexStylePython:
+		sb.WriteString(`b"`)
+		for _, c := range b {
+			sb.Write([]byte{'\\', 'x', digits[c>>4], digits[c&0x0f]})
+		}
+		sb.WriteByte('"')
+	default:
+		group := f.Group
+		if group <= 0 {
+			group = len(b) + 1
+			if f.Separator != "" {
+				group = 1
+			}
+		}
+		sb.Grow(len(b)*2 + (len(b)/group+1)*(len(f.Separator)+2))
+		for i, c := range b {
+			if i%group == 0 {
+				if i > 0 {
+					sb.WriteString(f.Separator)
+				}
+				if f.Prefix {
+					sb.WriteString("0x")
+				}
+			}
+			sb.Write([]byte{digits[c>>4], digits[c&0x0f]})
+		}
+	}
+	return sb.String()
+}
+
+// FromBinary returns a byte slice by the given binary string and desired byte size.
+// It returns nil for invalid binary strings. See DecodeBinary for errors and alignment.
+func FromBinary(str string, size int) []byte {
+	b, err := DecodeBinary(str, size, BinaryAlignNone)
+	if err != nil {
+		return nil
+	}
 	return b
 }
+
+// DecodeBinary returns a byte slice by the given binary string, desired byte size and alignment.
+// Whitespace characters, underscores and `0b` prefixes are accepted (i.e. "0b1010_0001 1111_0000").
+// The returned error is a *SyntaxError which points to the offending character position.
+func DecodeBinary(str string, size int, align BinaryAlign) ([]byte, error) {
+	bits := make([]byte, 0, len(str))
+	sep := true
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			sep = true
+		case c == '_':
+		case sep && c == '0' && i+1 < len(str) && (str[i+1] == 'b' || str[i+1] == 'B'):
+			i++
+			sep = false
+		case c == '0' || c == '1':
+			bits = append(bits, c-'0')
+			sep = false
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("invalid binary character %q", c)}
+		}
+	}
+	if r := len(bits) % 8; r != 0 {
+		switch align {
+		case BinaryAlignLeft:
+			bits = append(bits, make([]byte, 8-r)...)
+		case BinaryAlignRight:
+			bits = append(make([]byte, 8-r), bits...)
+		default:
+			return nil, &SyntaxError{Pos: len(str), Msg: fmt.Sprintf("bit string length %d is not a multiple of 8", len(bits))}
+		}
+	}
+
+	decoded := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		decoded[i/8] |= bit << (7 - uint(i%8))
+	}
+	return Resize(decoded, size), nil
+}
+
+// ToBinary returns the binary string of the given byte slice by the given desired byte size and format.
+// The size argument has the same semantics as the FromBinary size argument.
+func ToBinary(b []byte, size int, f BinaryFormat) string {
+	b = Resize(b, size)
+	group := f.Group
+	if group <= 0 {
+		group = len(b)*8 + 1
+		if f.Separator != "" {
+			group = 8
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(b)*8 + (len(b)*8/group+1)*(len(f.Separator)+2))
+	for i := 0; i < len(b)*8; i++ {
+		if i%group == 0 {
+			if i > 0 {
+				sb.WriteString(f.Separator)
+			}
+			if f.Prefix {
+				sb.WriteString("0b")
+			}
+		}
+		sb.WriteByte('0' + (b[i/8]>>(7-uint(i%8)))&1)
+	}
+	return sb.String()
+}
+
+// runeBoundary returns the largest rune boundary of the given string which is not greater than n.
+func runeBoundary(str string, n int) int {
+	if n >= len(str) {
+		return len(str)
+	}
+	for n > 0 && !utf8.RuneStart(str[n]) {
+		n--
+	}
+	return n
+}
+
+// graphemeBoundary returns the largest grapheme cluster boundary (approximation) of the given string
+// which is not greater than n.
+func graphemeBoundary(str string, n int) int {
+	n = runeBoundary(str, n)
+	for n > 0 && n < len(str) {
+		r, _ := utf8.DecodeRuneInString(str[n:])
+		prev, size := utf8.DecodeLastRuneInString(str[:n])
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) || r == 0x200d || (r >= 0x1f3fb && r <= 0x1f3ff):
+		case prev == 0x200d:
+		case prev == '\r' && r == '\n':
+		case isRegionalIndicator(prev) && isRegionalIndicator(r) && regionalIndicators(str[:n])%2 != 0:
+		default:
+			return n
+		}
+		n -= size
+	}
+	return n
+}
+
+// isRegionalIndicator returns whether the given rune is a regional indicator symbol.
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// regionalIndicators returns the number of the trailing regional indicator symbols of the given string.
+func regionalIndicators(str string) int {
+	n := 0
+	for len(str) > 0 {
+		r, size := utf8.DecodeLastRuneInString(str)
+		if !isRegionalIndicator(r) {
+			break
+		}
+		n++
+		str = str[:len(str)-size]
+	}
+	return n
+}
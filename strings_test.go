@@ -77,3 +77,320 @@ func BenchmarkFromHex(b *testing.B) {
 		byteman.FromHex("A", 1)
 	}
 }
+
+func TestDecodeHex(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		arg2 byteman.HexOptions
+		out  []byte
+	}{
+		{"deadbeef", 0, byteman.HexOptions{}, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"DEADBEEF", 2, byteman.HexOptions{}, []byte{0xde, 0xad}},
+		{"deadbeef", -1, byteman.HexOptions{}, []byte{0xde, 0xad, 0xbe}},
+		{"deadbeef", 5, byteman.HexOptions{}, []byte{0xde, 0xad, 0xbe, 0xef, 0x00}},
+		{"abc", 0, byteman.HexOptions{Padding: byteman.HexPaddingLeft}, []byte{0x0a, 0xbc}},
+		{"abc", 0, byteman.HexOptions{Padding: byteman.HexPaddingRight}, []byte{0xab, 0xc0}},
+		{"0xdeadbeef", 0, byteman.HexOptions{Prefix: true}, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"de ad\tbe\nef", 0, byteman.HexOptions{Whitespace: true}, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"de:ad-be:ef", 0, byteman.HexOptions{Separators: ":-"}, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{"{0xDE, 0xAD, 0xB, 0xEF,}", 0, byteman.HexOptions{Array: true}, []byte{0xde, 0xad, 0x0b, 0xef}},
+		{" [0xde,0xad] ", 0, byteman.HexOptions{Array: true}, []byte{0xde, 0xad}},
+		{"0xDE, 0xAD", 0, byteman.HexOptions{Lenient: true}, []byte{0xde, 0xad}},
+		{"00:1a:2b-3c 4d", 0, byteman.HexOptions{Lenient: true}, []byte{0x00, 0x1a, 0x2b, 0x3c, 0x4d}},
+		{"", 0, byteman.HexOptions{Lenient: true}, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.DecodeHex(v.arg0, v.arg1, v.arg2)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	errs := []struct {
+		arg0 string
+		arg1 byteman.HexOptions
+		pos  int
+	}{
+		{"abc", byteman.HexOptions{}, 3},
+		{"abxd", byteman.HexOptions{}, 2},
+		{"ab cd", byteman.HexOptions{}, 2},
+		{"0xab", byteman.HexOptions{}, 1},
+		{"ab 0x", byteman.HexOptions{Lenient: true}, 5},
+		{"0xab0xcd", byteman.HexOptions{Lenient: true}, 5},
+		{"{0xab; 0xcd}", byteman.HexOptions{Array: true}, 5},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeHex(v.arg0, 0, v.arg1)
+		if e, ok := err.(*byteman.SyntaxError); !ok {
+			t.Errorf("got %v, want SyntaxError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func BenchmarkDecodeHex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.DecodeHex("0xDE, 0xAD, 0xBE, 0xEF", 0, byteman.HexOptions{Lenient: true})
+	}
+}
+
+func TestToHex(t *testing.T) {
+	b := []byte{0xde, 0xad, 0xbe, 0xef}
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.HexFormat
+		out  string
+	}{
+		{b, 0, byteman.HexFormat{}, "deadbeef"},
+		{b, 0, byteman.HexFormat{Uppercase: true}, "DEADBEEF"},
+		{b, 2, byteman.HexFormat{}, "dead"},
+		{b, -1, byteman.HexFormat{}, "deadbe"},
+		{b, 5, byteman.HexFormat{}, "deadbeef00"},
+		{b, 0, byteman.HexFormat{Prefix: true}, "0xdeadbeef"},
+		{b, 0, byteman.HexFormat{Separator: ":"}, "de:ad:be:ef"},
+		{b, 0, byteman.HexFormat{Separator: " ", Group: 2}, "dead beef"},
+		{b, 0, byteman.HexFormat{Separator: ", ", Prefix: true, Uppercase: true}, "0xDE, 0xAD, 0xBE, 0xEF"},
+		{b, 0, byteman.HexFormat{Style: byteman.HexStyleC}, "{0xde, 0xad, 0xbe, 0xef}"},
+		{b, 0, byteman.HexFormat{Style: byteman.HexStyleGo, Uppercase: true}, "[]byte{0xDE, 0xAD, 0xBE, 0xEF}"},
+		{b, 0, byteman.HexFormat{Style: byteman.HexStylePython}, `b"\xde\xad\xbe\xef"`},
+		{[]byte{}, 0, byteman.HexFormat{Separator: ":"}, ""},
+		{[]byte{}, 0, byteman.HexFormat{Style: byteman.HexStyleC}, "{}"},
+		{nil, 0, byteman.HexFormat{Prefix: true}, ""},
+	}
+	for _, v := range table {
+		s := byteman.ToHex(v.arg0, v.arg1, v.arg2)
+		if s != v.out {
+			t.Errorf("got %v, want %v", s, v.out)
+		}
+	}
+
+	// Round trip
+	for _, f := range []byteman.HexFormat{{}, {Separator: "-"}, {Style: byteman.HexStyleC}, {Prefix: true, Separator: " ", Group: 3}} {
+		s := byteman.ToHex(b, 0, f)
+		if d, err := byteman.DecodeHex(s, 0, byteman.HexOptions{Lenient: true}); err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(d, b) {
+			t.Errorf("got %v, want %v", d, b)
+		}
+	}
+}
+
+func BenchmarkToHex(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.ToHex([]byte("foo"), 0, byteman.HexFormat{Separator: ":"})
+	}
+}
+
+func TestFromBinary(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"10100001", 0, []byte{0xa1}},
+		{"0b1010_0001 1111_0000", 0, []byte{0xa1, 0xf0}},
+		{"0b10100001 0b11110000", 1, []byte{0xa1}},
+		{"1010_0001", 2, []byte{0xa1, 0x00}},
+		{"1010_0001 1111_0000", -1, []byte{0xa1}},
+		{"", 0, []byte{}},
+	}
+	for _, v := range table {
+		b := byteman.FromBinary(v.arg0, v.arg1)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	for _, s := range []string{"101", "1010 0012", "0b0b10100001"} {
+		if b := byteman.FromBinary(s, 0); b != nil {
+			t.Errorf("got %v, want nil", b)
+		}
+	}
+}
+
+func BenchmarkFromBinary(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.FromBinary("0b1010_0001 1111_0000", 0)
+	}
+}
+
+func TestDecodeBinary(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 byteman.BinaryAlign
+		out  []byte
+	}{
+		{"101", byteman.BinaryAlignLeft, []byte{0xa0}},
+		{"101", byteman.BinaryAlignRight, []byte{0x05}},
+		{"1_0000_0001", byteman.BinaryAlignLeft, []byte{0x80, 0x80}},
+		{"1_0000_0001", byteman.BinaryAlignRight, []byte{0x01, 0x01}},
+		{"1010_0001", byteman.BinaryAlignNone, []byte{0xa1}},
+	}
+	for _, v := range table {
+		b, err := byteman.DecodeBinary(v.arg0, 0, v.arg1)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	errs := []struct {
+		arg0 string
+		arg1 byteman.BinaryAlign
+		pos  int
+	}{
+		{"101", byteman.BinaryAlignNone, 3},
+		{"1010 2", byteman.BinaryAlignLeft, 5},
+		{"10 0x1", byteman.BinaryAlignLeft, 4},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeBinary(v.arg0, 0, v.arg1)
+		if e, ok := err.(*byteman.SyntaxError); !ok {
+			t.Errorf("got %v, want SyntaxError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func TestToBinary(t *testing.T) {
+	b := []byte{0xa1, 0xf0}
+	table := []struct {
+		arg0 []byte
+		arg1 int
+		arg2 byteman.BinaryFormat
+		out  string
+	}{
+		{b, 0, byteman.BinaryFormat{}, "1010000111110000"},
+		{b, 1, byteman.BinaryFormat{}, "10100001"},
+		{b, 3, byteman.BinaryFormat{Separator: " "}, "10100001 11110000 00000000"},
+		{b, 0, byteman.BinaryFormat{Separator: "_", Group: 4}, "1010_0001_1111_0000"},
+		{b, 0, byteman.BinaryFormat{Separator: " ", Prefix: true}, "0b10100001 0b11110000"},
+		{b, 0, byteman.BinaryFormat{Prefix: true}, "0b1010000111110000"},
+		{nil, 0, byteman.BinaryFormat{Prefix: true}, ""},
+	}
+	for _, v := range table {
+		s := byteman.ToBinary(v.arg0, v.arg1, v.arg2)
+		if s != v.out {
+			t.Errorf("got %v, want %v", s, v.out)
+		}
+		if d := byteman.FromBinary(s, 0); v.arg1 == 0 && !bytes.Equal(d, v.arg0) && len(v.arg0) > 0 {
+			t.Errorf("got %v, want %v", d, v.arg0)
+		}
+	}
+}
+
+func BenchmarkToBinary(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.ToBinary([]byte("foo"), 0, byteman.BinaryFormat{Separator: " "})
+	}
+}
+
+func TestFromStringRuneSafe(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"foo", 0, []byte("foo")},
+		{"foo", 2, []byte("fo")},
+		{"foo", 4, []byte{0x66, 0x6f, 0x6f, 0x00}},
+		{"héllo", 2, []byte{0x68, 0x00}},
+		{"héllo", 3, []byte("hé")},
+		{"héllo", -4, []byte{0x68, 0x00}},
+		{"日本", 5, []byte{0xe6, 0x97, 0xa5, 0x00, 0x00}},
+		{"😀", 3, []byte{0x00, 0x00, 0x00}},
+		{"", 2, []byte{0x00, 0x00}},
+	}
+	for _, v := range table {
+		b := byteman.FromStringRuneSafe(v.arg0, v.arg1)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+}
+
+func BenchmarkFromStringRuneSafe(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.FromStringRuneSafe("héllo", 2)
+	}
+}
+
+func TestFromStringGraphemeSafe(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		out  []byte
+	}{
+		{"foo", 2, []byte("fo")},
+		{"héllo", 2, []byte{0x68, 0x00}},
+		// "e" followed by U+0301 COMBINING ACUTE ACCENT
+		{"ae\u0301x", 3, []byte{0x61, 0x00, 0x00}},
+		{"ae\u0301x", 4, []byte("ae\u0301")},
+		{"ae\u0301x", -2, []byte{0x61, 0x00, 0x00}},
+		// U+1F44D THUMBS UP SIGN followed by U+1F3FD skin tone modifier
+		{"a\U0001f44d\U0001f3fd", 5, []byte{0x61, 0x00, 0x00, 0x00, 0x00}},
+		// U+1F468 U+200D U+1F469 (ZWJ sequence)
+		{"a\U0001f468\u200d\U0001f469", 8, []byte{0x61, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		// Regional indicator pairs (flags)
+		{"\U0001f1fa\U0001f1f8\U0001f1e9\U0001f1ea", 12, append([]byte("\U0001f1fa\U0001f1f8"), 0x00, 0x00, 0x00, 0x00)},
+		{"\U0001f1fa\U0001f1f8\U0001f1e9\U0001f1ea", 16, []byte("\U0001f1fa\U0001f1f8\U0001f1e9\U0001f1ea")},
+		{"a\r\n", 2, []byte{0x61, 0x00}},
+	}
+	for _, v := range table {
+		b := byteman.FromStringGraphemeSafe(v.arg0, v.arg1)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+}
+
+func TestFromStringWith(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		arg2 byteman.ResizeOptions
+		out  []byte
+	}{
+		{"foo", 6, byteman.ResizeOptions{Fill: ' '}, []byte("foo   ")},
+		{"123", 6, byteman.ResizeOptions{Fill: ' ', PadLeft: true}, []byte("   123")},
+		{"foobar", 3, byteman.ResizeOptions{TruncateLeft: true}, []byte("bar")},
+		{"foo", 0, byteman.ResizeOptions{Fill: ' '}, []byte("foo")},
+	}
+	for _, v := range table {
+		b := byteman.FromStringWith(v.arg0, v.arg1, v.arg2)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+}
+
+func TestFromHexWith(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		arg2 byteman.ResizeOptions
+		out  []byte
+	}{
+		{"0102", 4, byteman.ResizeOptions{Fill: 0xff}, []byte{0x01, 0x02, 0xff, 0xff}},
+		{"0102", 4, byteman.ResizeOptions{PadLeft: true}, []byte{0x00, 0x00, 0x01, 0x02}},
+		{"010203", 2, byteman.ResizeOptions{TruncateLeft: true}, []byte{0x02, 0x03}},
+		{"010203", -1, byteman.ResizeOptions{TruncateLeft: true}, []byte{0x02, 0x03}},
+	}
+	for _, v := range table {
+		b := byteman.FromHexWith(v.arg0, v.arg1, v.arg2)
+		if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	if b := byteman.FromHexWith("9", 1, byteman.ResizeOptions{}); b != nil {
+		t.Errorf("got %v, want nil", b)
+	}
+}
@@ -0,0 +1,64 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"crypto/subtle"
+	"runtime"
+)
+
+// ConstantTimeEqual returns whether the given byte slices are equal.
+// The time taken is independent of the contents but not of the lengths.
+func ConstantTimeEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// ConstantTimeLessThan returns whether the given byte slice is less than the other one by the given
+// byte order (endianness), treating both as unsigned integers. It returns false if the lengths differ.
+// The time taken is independent of the contents but not of the lengths.
+func ConstantTimeLessThan(a, b []byte, bo ByteOrder) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	lt := 0
+	for i := 0; i < len(a); i++ {
+		// Iterate from the least significant byte so the most significant difference wins.
+		j := i
+		if bo.Type() == ByteOrderTypeBigEndian {
+			j = len(a) - 1 - i
+		}
+		x, y := int(a[j]), int(b[j])
+		lt = subtle.ConstantTimeSelect(subtle.ConstantTimeByteEq(a[j], b[j]), lt, int(uint(x-y)>>(IntSize-1)))
+	}
+	return lt == 1
+}
+
+// ConstantTimeSelect returns a copy of x if v is 1 or a copy of y if v is 0.
+// It returns nil if the lengths differ. The behavior is undefined if v takes any other value.
+func ConstantTimeSelect(v int, x, y []byte) []byte {
+	if len(x) != len(y) {
+		return nil
+	}
+	b := make([]byte, len(y))
+	copy(b, y)
+	subtle.ConstantTimeCopy(v, b, x)
+	return b
+}
+
+// Wipe zeroes the given byte slice.
+//
+//go:noinline
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
+
+// ResizeWipe resizes the given byte slice (see Resize) and wipes its whole backing array.
+func ResizeWipe(b []byte, size int) []byte {
+	nb := Resize(b, size)
+	Wipe(b[:cap(b)])
+	return nb
+}
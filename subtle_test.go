@@ -0,0 +1,94 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestConstantTimeEqual(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 []byte
+		out  bool
+	}{
+		{[]byte("foo"), []byte("foo"), true},
+		{[]byte("foo"), []byte("bar"), false},
+		{[]byte("foo"), []byte("fo"), false},
+		{[]byte{}, []byte{}, true},
+	}
+	for _, v := range table {
+		if r := byteman.ConstantTimeEqual(v.arg0, v.arg1); r != v.out {
+			t.Errorf("got %v, want %v", r, v.out)
+		}
+	}
+}
+
+func BenchmarkConstantTimeEqual(b *testing.B) {
+	x, y := bytes.Repeat([]byte("a"), 32), bytes.Repeat([]byte("a"), 32)
+	for i := 0; i < b.N; i++ {
+		byteman.ConstantTimeEqual(x, y)
+	}
+}
+
+func TestConstantTimeLessThan(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 []byte
+		arg2 byteman.ByteOrder
+		out  bool
+	}{
+		{[]byte{0x01, 0x02}, []byte{0x01, 0x03}, &byteman.BigEndian{}, true},
+		{[]byte{0x01, 0x03}, []byte{0x01, 0x02}, &byteman.BigEndian{}, false},
+		{[]byte{0x01, 0x02}, []byte{0x01, 0x02}, &byteman.BigEndian{}, false},
+		{[]byte{0x00, 0xff}, []byte{0x01, 0x00}, &byteman.BigEndian{}, true},
+		{[]byte{0x00, 0xff}, []byte{0x01, 0x00}, &byteman.LittleEndian{}, false},
+		{[]byte{0xff, 0x00}, []byte{0x00, 0x01}, &byteman.LittleEndian{}, true},
+		{[]byte{0x01}, []byte{0x01, 0x02}, &byteman.BigEndian{}, false},
+		{[]byte{}, []byte{}, &byteman.BigEndian{}, false},
+	}
+	for _, v := range table {
+		if r := byteman.ConstantTimeLessThan(v.arg0, v.arg1, v.arg2); r != v.out {
+			t.Errorf("got %v, want %v for %v < %v", r, v.out, v.arg0, v.arg1)
+		}
+	}
+}
+
+func TestConstantTimeSelect(t *testing.T) {
+	x, y := []byte("foo"), []byte("bar")
+	if b := byteman.ConstantTimeSelect(1, x, y); !bytes.Equal(b, x) {
+		t.Errorf("got %v, want %v", b, x)
+	}
+	if b := byteman.ConstantTimeSelect(0, x, y); !bytes.Equal(b, y) {
+		t.Errorf("got %v, want %v", b, y)
+	}
+	if b := byteman.ConstantTimeSelect(1, x, []byte("ba")); b != nil {
+		t.Errorf("got %v, want nil", b)
+	}
+}
+
+func TestWipe(t *testing.T) {
+	b := []byte("secret")
+	byteman.Wipe(b)
+	if !bytes.Equal(b, make([]byte, 6)) {
+		t.Errorf("got %v, want %v", b, make([]byte, 6))
+	}
+	byteman.Wipe(nil)
+}
+
+func TestResizeWipe(t *testing.T) {
+	b := make([]byte, 6, 8)
+	copy(b, "secret")
+	full := b[:cap(b)]
+	nb := byteman.ResizeWipe(b, 3)
+	if !bytes.Equal(nb, []byte("sec")) {
+		t.Errorf("got %v, want %v", nb, []byte("sec"))
+	}
+	if !bytes.Equal(full, make([]byte, 8)) {
+		t.Errorf("got %v, want %v", full, make([]byte, 8))
+	}
+}
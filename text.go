@@ -0,0 +1,210 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding represents the text encoding.
+type TextEncoding uint8
+
+const (
+	// TextEncodingUTF8 represents the UTF-8 encoding.
+	TextEncodingUTF8 TextEncoding = 0
+	// TextEncodingUTF16LE represents the little-endian UTF-16 encoding.
+	TextEncodingUTF16LE TextEncoding = 1
+	// TextEncodingUTF16BE represents the big-endian UTF-16 encoding.
+	TextEncodingUTF16BE TextEncoding = 2
+	// TextEncodingLatin1 represents the ISO-8859-1 encoding.
+	TextEncodingLatin1 TextEncoding = 3
+	// TextEncodingWindows1252 represents the Windows-1252 (CP1252) encoding.
+	TextEncodingWindows1252 TextEncoding = 4
+)
+
+// TextOptions represents the text encoding options.
+type TextOptions struct {
+	// BOM emits a byte order mark while encoding, and detects and strips it while decoding.
+	// A detected UTF-16 byte order mark overrides the byte order of the given UTF-16 encoding.
+	BOM bool
+	// Strict returns a *TextError for unmappable characters and invalid sequences instead of replacing them.
+	Strict bool
+	// Replacement is the replacement character.
+	// Default is `?` for Latin-1 and Windows-1252 encoding, otherwise U+FFFD.
+	Replacement rune
+}
+
+// windows1252 represents the Windows-1252 characters between 0x80 and 0x9f (0 for undefined characters).
+var windows1252 = [32]rune{
+	0x20ac, 0, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021, 0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017d, 0,
+	0, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014, 0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0, 0x017e, 0x0178,
+}
+
+// EncodeString returns a byte slice by the given string, desired byte size, text encoding and options.
+// The size argument has the same semantics as the FromString size argument and it is applied after encoding.
+func EncodeString(str string, size int, enc TextEncoding, opts TextOptions) ([]byte, error) {
+	b := make([]byte, 0, len(str)*2)
+	if opts.BOM {
+		switch enc {
+		case TextEncodingUTF8:
+			b = append(b, 0xef, 0xbb, 0xbf)
+		case TextEncodingUTF16LE:
+			b = append(b, 0xff, 0xfe)
+		case TextEncodingUTF16BE:
+			b = append(b, 0xfe, 0xff)
+		}
+	}
+
+	for i := 0; i < len(str); {
+		r, n := utf8.DecodeRuneInString(str[i:])
+		if r == utf8.RuneError && n == 1 {
+			if opts.Strict {
+				return nil, &TextError{Pos: i, Msg: "invalid UTF-8 sequence"}
+			}
+			r = replacementRune(enc, opts)
+		}
+		var ok bool
+		if b, ok = appendRune(b, r, enc); !ok {
+			if opts.Strict {
+				return nil, &TextError{Pos: i, Msg: fmt.Sprintf("unmappable character %U", r)}
+			}
+			if b, ok = appendRune(b, replacementRune(enc, opts), enc); !ok {
+				return nil, &TextError{Pos: i, Msg: "unmappable replacement character"}
+			}
+		}
+		i += n
+	}
+	return Resize(b, size), nil
+}
+
+// DecodeString returns a string by the given byte slice, text encoding and options.
+func DecodeString(b []byte, enc TextEncoding, opts TextOptions) (string, error) {
+	if opts.BOM {
+		switch {
+		case enc == TextEncodingUTF8 && len(b) >= 3 && b[0] == 0xef && b[1] == 0xbb && b[2] == 0xbf:
+			b = b[3:]
+		case (enc == TextEncodingUTF16LE || enc == TextEncodingUTF16BE) && len(b) >= 2 && b[0] == 0xff && b[1] == 0xfe:
+			enc, b = TextEncodingUTF16LE, b[2:]
+		case (enc == TextEncodingUTF16LE || enc == TextEncodingUTF16BE) && len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff:
+			enc, b = TextEncodingUTF16BE, b[2:]
+		}
+	}
+	repl := opts.Replacement
+	if repl == 0 {
+		repl = utf8.RuneError
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(b))
+	switch enc {
+	case TextEncodingUTF16LE, TextEncodingUTF16BE:
+		var bo ByteOrder = &LittleEndian{}
+		if enc == TextEncodingUTF16BE {
+			bo = &BigEndian{}
+		}
+		for i := 0; i < len(b); i += 2 {
+			if i+1 >= len(b) {
+				if opts.Strict {
+					return "", &TextError{Pos: i, Msg: "incomplete UTF-16 code unit"}
+				}
+				sb.WriteRune(repl)
+				break
+			}
+			r := rune(Uint16(b[i:i+2], bo))
+			if utf16.IsSurrogate(r) {
+				var r2 rune
+				if i+3 < len(b) {
+					r2 = rune(Uint16(b[i+2:i+4], bo))
+				}
+				if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+					if opts.Strict {
+						return "", &TextError{Pos: i, Msg: "invalid UTF-16 surrogate pair"}
+					}
+					r = repl
+				} else {
+					i += 2
+				}
+			}
+			sb.WriteRune(r)
+		}
+	case TextEncodingLatin1:
+		for _, c := range b {
+			sb.WriteRune(rune(c))
+		}
+	case TextEncodingWindows1252:
+		for i, c := range b {
+			r := rune(c)
+			if c >= 0x80 && c <= 0x9f {
+				if r = windows1252[c-0x80]; r == 0 {
+					if opts.Strict {
+						return "", &TextError{Pos: i, Msg: fmt.Sprintf("undefined Windows-1252 character %#02x", c)}
+					}
+					r = repl
+				}
+			}
+			sb.WriteRune(r)
+		}
+	default:
+		for i := 0; i < len(b); {
+			r, n := utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && n == 1 {
+				if opts.Strict {
+					return "", &TextError{Pos: i, Msg: "invalid UTF-8 sequence"}
+				}
+				r = repl
+			}
+			sb.WriteRune(r)
+			i += n
+		}
+	}
+	return sb.String(), nil
+}
+
+// appendRune appends the given rune by the given text encoding.
+// It returns false if the rune is not mappable.
+func appendRune(b []byte, r rune, enc TextEncoding) ([]byte, bool) {
+	switch enc {
+	case TextEncodingUTF16LE, TextEncodingUTF16BE:
+		var bo ByteOrder = &LittleEndian{}
+		if enc == TextEncodingUTF16BE {
+			bo = &BigEndian{}
+		}
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			return append(append(b, FromUint(uint16(r1), bo)...), FromUint(uint16(r2), bo)...), true
+		}
+		return append(b, FromUint(uint16(r), bo)...), true
+	case TextEncodingLatin1:
+		if r > 0xff {
+			return b, false
+		}
+		return append(b, byte(r)), true
+	case TextEncodingWindows1252:
+		if r < 0x80 || (r >= 0xa0 && r <= 0xff) {
+			return append(b, byte(r)), true
+		}
+		for i, v := range windows1252 {
+			if v != 0 && v == r {
+				return append(b, byte(0x80+i)), true
+			}
+		}
+		return b, false
+	default:
+		var buf [utf8.UTFMax]byte
+		return append(b, buf[:utf8.EncodeRune(buf[:], r)]...), true
+	}
+}
+
+// replacementRune returns the replacement character by the given text encoding and options.
+func replacementRune(enc TextEncoding, opts TextOptions) rune {
+	if opts.Replacement != 0 {
+		return opts.Replacement
+	}
+	if enc == TextEncodingLatin1 || enc == TextEncodingWindows1252 {
+		return '?'
+	}
+	return utf8.RuneError
+}
@@ -0,0 +1,131 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+func TestEncodeString(t *testing.T) {
+	table := []struct {
+		arg0 string
+		arg1 int
+		arg2 byteman.TextEncoding
+		arg3 byteman.TextOptions
+		out  []byte
+	}{
+		{"héllo", 0, byteman.TextEncodingUTF8, byteman.TextOptions{}, []byte("héllo")},
+		{"hé", 0, byteman.TextEncodingUTF8, byteman.TextOptions{BOM: true}, []byte{0xef, 0xbb, 0xbf, 0x68, 0xc3, 0xa9}},
+		{"hé", 0, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, []byte{0x68, 0x00, 0xe9, 0x00}},
+		{"hé", 0, byteman.TextEncodingUTF16BE, byteman.TextOptions{}, []byte{0x00, 0x68, 0x00, 0xe9}},
+		{"hé", 0, byteman.TextEncodingUTF16LE, byteman.TextOptions{BOM: true}, []byte{0xff, 0xfe, 0x68, 0x00, 0xe9, 0x00}},
+		{"hé", 0, byteman.TextEncodingUTF16BE, byteman.TextOptions{BOM: true}, []byte{0xfe, 0xff, 0x00, 0x68, 0x00, 0xe9}},
+		{"😀", 0, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, []byte{0x3d, 0xd8, 0x00, 0xde}},
+		{"hé", 8, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, []byte{0x68, 0x00, 0xe9, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"hé", -2, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, []byte{0x68, 0x00}},
+		{"hé", 0, byteman.TextEncodingLatin1, byteman.TextOptions{}, []byte{0x68, 0xe9}},
+		{"h€", 0, byteman.TextEncodingLatin1, byteman.TextOptions{}, []byte{0x68, '?'}},
+		{"h€", 0, byteman.TextEncodingLatin1, byteman.TextOptions{Replacement: '_'}, []byte{0x68, '_'}},
+		{"h€é", 0, byteman.TextEncodingWindows1252, byteman.TextOptions{}, []byte{0x68, 0x80, 0xe9}},
+		{"“ok”", 0, byteman.TextEncodingWindows1252, byteman.TextOptions{}, []byte{0x93, 0x6f, 0x6b, 0x94}},
+		{"h😀", 0, byteman.TextEncodingWindows1252, byteman.TextOptions{}, []byte{0x68, '?'}},
+		{"h\xff", 0, byteman.TextEncodingUTF16BE, byteman.TextOptions{}, []byte{0x00, 0x68, 0xff, 0xfd}},
+		{"", 0, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, []byte{}},
+	}
+	for _, v := range table {
+		b, err := byteman.EncodeString(v.arg0, v.arg1, v.arg2, v.arg3)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if !bytes.Equal(b, v.out) {
+			t.Errorf("got %v, want %v", b, v.out)
+		}
+	}
+
+	errs := []struct {
+		arg0 string
+		arg1 byteman.TextEncoding
+		pos  int
+	}{
+		{"hé€", byteman.TextEncodingLatin1, 3},
+		{"h😀", byteman.TextEncodingWindows1252, 1},
+		{"h\xff", byteman.TextEncodingUTF8, 1},
+		{"h\xff", byteman.TextEncodingUTF16LE, 1},
+	}
+	for _, v := range errs {
+		_, err := byteman.EncodeString(v.arg0, 0, v.arg1, byteman.TextOptions{Strict: true})
+		if e, ok := err.(*byteman.TextError); !ok {
+			t.Errorf("got %v, want TextError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func BenchmarkEncodeString(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		byteman.EncodeString("foo", 0, byteman.TextEncodingUTF16LE, byteman.TextOptions{})
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	table := []struct {
+		arg0 []byte
+		arg1 byteman.TextEncoding
+		arg2 byteman.TextOptions
+		out  string
+	}{
+		{[]byte("héllo"), byteman.TextEncodingUTF8, byteman.TextOptions{}, "héllo"},
+		{[]byte{0xef, 0xbb, 0xbf, 0x68}, byteman.TextEncodingUTF8, byteman.TextOptions{BOM: true}, "h"},
+		{[]byte{0xef, 0xbb, 0xbf, 0x68}, byteman.TextEncodingUTF8, byteman.TextOptions{}, "\ufeffh"},
+		{[]byte{0x68, 0x00, 0xe9, 0x00}, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, "hé"},
+		{[]byte{0x00, 0x68, 0x00, 0xe9}, byteman.TextEncodingUTF16BE, byteman.TextOptions{}, "hé"},
+		{[]byte{0xfe, 0xff, 0x00, 0x68}, byteman.TextEncodingUTF16LE, byteman.TextOptions{BOM: true}, "h"},
+		{[]byte{0xff, 0xfe, 0x68, 0x00}, byteman.TextEncodingUTF16BE, byteman.TextOptions{BOM: true}, "h"},
+		{[]byte{0x3d, 0xd8, 0x00, 0xde}, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, "😀"},
+		{[]byte{0x3d, 0xd8, 0x68, 0x00}, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, "�h"},
+		{[]byte{0x68, 0x00, 0x69}, byteman.TextEncodingUTF16LE, byteman.TextOptions{}, "h�"},
+		{[]byte{0x68, 0xe9, 0x80}, byteman.TextEncodingLatin1, byteman.TextOptions{}, "hé\u0080"},
+		{[]byte{0x68, 0xe9, 0x80}, byteman.TextEncodingWindows1252, byteman.TextOptions{}, "hé€"},
+		{[]byte{0x68, 0x81}, byteman.TextEncodingWindows1252, byteman.TextOptions{Replacement: '?'}, "h?"},
+		{[]byte{0x68, 0xff}, byteman.TextEncodingUTF8, byteman.TextOptions{}, "h�"},
+		{[]byte{}, byteman.TextEncodingUTF16BE, byteman.TextOptions{BOM: true}, ""},
+	}
+	for _, v := range table {
+		s, err := byteman.DecodeString(v.arg0, v.arg1, v.arg2)
+		if err != nil {
+			t.Errorf("got %v, want nil", err)
+		} else if s != v.out {
+			t.Errorf("got %q, want %q", s, v.out)
+		}
+	}
+
+	errs := []struct {
+		arg0 []byte
+		arg1 byteman.TextEncoding
+		pos  int
+	}{
+		{[]byte{0x68, 0xff}, byteman.TextEncodingUTF8, 1},
+		{[]byte{0x68, 0x00, 0x69}, byteman.TextEncodingUTF16LE, 2},
+		{[]byte{0x68, 0x00, 0x00, 0xdc}, byteman.TextEncodingUTF16LE, 2},
+		{[]byte{0x68, 0x8d}, byteman.TextEncodingWindows1252, 1},
+	}
+	for _, v := range errs {
+		_, err := byteman.DecodeString(v.arg0, v.arg1, byteman.TextOptions{Strict: true})
+		if e, ok := err.(*byteman.TextError); !ok {
+			t.Errorf("got %v, want TextError", err)
+		} else if e.Pos != v.pos {
+			t.Errorf("got %v, want %v", e.Pos, v.pos)
+		}
+	}
+}
+
+func BenchmarkDecodeString(b *testing.B) {
+	data := []byte{0x66, 0x00, 0x6f, 0x00, 0x6f, 0x00}
+	for i := 0; i < b.N; i++ {
+		byteman.DecodeString(data, byteman.TextEncodingUTF16LE, byteman.TextOptions{})
+	}
+}
@@ -0,0 +1,384 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxTLVDepth represents the maximum nesting depth of constructed TLV values.
+const maxTLVDepth = 64
+
+// TLVTagType represents the TLV tag type.
+type TLVTagType uint8
+
+const (
+	// TLVTagUint8 represents the 1 byte tag.
+	TLVTagUint8 TLVTagType = 0
+	// TLVTagUint16 represents the 2 byte tag.
+	TLVTagUint16 TLVTagType = 1
+	// TLVTagBER represents the BER (X.690) multi-byte tag (i.e. EMV tags such as 0x9f02).
+	// The tag value is the tag bytes as a big-endian integer.
+	TLVTagBER TLVTagType = 2
+)
+
+// TLVLengthType represents the TLV length type.
+type TLVLengthType uint8
+
+const (
+	// TLVLengthUint8 represents the 1 byte length.
+	TLVLengthUint8 TLVLengthType = 0
+	// TLVLengthUint16 represents the 2 byte length.
+	TLVLengthUint16 TLVLengthType = 1
+	// TLVLengthUint32 represents the 4 byte length.
+	TLVLengthUint32 TLVLengthType = 2
+	// TLVLengthBER represents the BER (X.690) definite short or long form length.
+	TLVLengthBER TLVLengthType = 3
+	// TLVLengthVarint represents the unsigned LEB128 varint length.
+	TLVLengthVarint TLVLengthType = 4
+)
+
+// TLVFormat represents the TLV format.
+type TLVFormat struct {
+	// Tag is the tag type.
+	Tag TLVTagType
+	// Length is the length type.
+	Length TLVLengthType
+	// ByteOrder is the byte order of fixed width tags and lengths. Default is big-endian.
+	ByteOrder ByteOrder
+	// Constructed reports whether the value of the given tag is a nested TLV sequence.
+	// Default is the BER constructed bit (0x20 of the first tag byte) for BER tags, otherwise no nesting.
+	Constructed func(tag uint64) bool
+}
+
+// TLV represents a parsed TLV node.
+type TLV struct {
+	// Tag is the tag.
+	Tag uint64
+	// Value is the value. It shares the underlying array of the parsed byte slice.
+	Value []byte
+	// Children is the parsed value of a constructed TLV.
+	Children []*TLV
+	// Constructed reports whether the TLV is constructed.
+	Constructed bool
+	// Offset is the byte offset of the TLV (tag) in the parsed byte slice.
+	Offset int
+	// ValueOffset is the byte offset of the value in the parsed byte slice.
+	ValueOffset int
+}
+
+// ParseTLV returns the TLV nodes by the given byte slice and TLV format.
+// It returns io.ErrUnexpectedEOF for truncated tags or lengths and *LengthError for values which exceed the available bytes.
+func ParseTLV(b []byte, f TLVFormat) ([]*TLV, error) {
+	return parseTLV(b, 0, f, 0)
+}
+
+// parseTLV returns the TLV nodes of the given byte slice at the given offset and depth.
+func parseTLV(b []byte, offset int, f TLVFormat, depth int) ([]*TLV, error) {
+	if depth > maxTLVDepth {
+		return nil, fmt.Errorf("byteman: TLV nesting exceeds %d levels", maxTLVDepth)
+	}
+	var nodes []*TLV
+	for i := 0; i < len(b); {
+		tag, tn, err := readTLVTag(b[i:], f)
+		if err != nil {
+			return nil, err
+		}
+		length, ln, err := readTLVLength(b[i+tn:], f)
+		if err != nil {
+			return nil, err
+		}
+		start := i + tn + ln
+		if available := len(b) - start; length > uint64(available) {
+			return nil, &LengthError{Declared: length, Available: available}
+		}
+		node := &TLV{
+			Tag:         tag,
+			Value:       b[start : start+int(length)],
+			Constructed: isConstructedTLV(tag, f),
+			Offset:      offset + i,
+			ValueOffset: offset + start,
+		}
+		if node.Constructed {
+			if node.Children, err = parseTLV(node.Value, node.ValueOffset, f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		nodes = append(nodes, node)
+		i = start + int(length)
+	}
+	return nodes, nil
+}
+
+// FindTLV returns the first TLV node (depth-first) by the given tag, or nil if there is no such node.
+func FindTLV(nodes []*TLV, tag uint64) *TLV {
+	for _, n := range nodes {
+		if n.Tag == tag {
+			return n
+		}
+		if c := FindTLV(n.Children, tag); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// WalkTLV calls the given function for each TLV node (depth-first) with its depth.
+// The children of a node are skipped if the function returns false.
+func WalkTLV(nodes []*TLV, fn func(node *TLV, depth int) bool) {
+	walkTLV(nodes, fn, 0)
+}
+
+// walkTLV calls the given function for each TLV node by the given depth.
+func walkTLV(nodes []*TLV, fn func(node *TLV, depth int) bool, depth int) {
+	for _, n := range nodes {
+		if fn(n, depth) {
+			walkTLV(n.Children, fn, depth+1)
+		}
+	}
+}
+
+// TLVBuilder represents a TLV builder which computes the lengths of nested values.
+type TLVBuilder struct {
+	f      TLVFormat
+	frames [][]byte
+	tags   []uint64
+	err    error
+}
+
+// NewTLVBuilder returns a new TLV builder by the given TLV format.
+func NewTLVBuilder(f TLVFormat) *TLVBuilder {
+	return &TLVBuilder{f: f, frames: [][]byte{nil}}
+}
+
+// Add adds a TLV by the given tag and value.
+func (tb *TLVBuilder) Add(tag uint64, value []byte) *TLVBuilder {
+	if tb.err != nil {
+		return tb
+	}
+	top := len(tb.frames) - 1
+	tb.frames[top], tb.err = appendTLV(tb.frames[top], tag, value, tb.f)
+	return tb
+}
+
+// Begin begins a constructed TLV by the given tag. The following TLVs are nested until End is called.
+func (tb *TLVBuilder) Begin(tag uint64) *TLVBuilder {
+	if tb.err != nil {
+		return tb
+	}
+	tb.frames = append(tb.frames, nil)
+	tb.tags = append(tb.tags, tag)
+	return tb
+}
+
+// End ends the last begun constructed TLV.
+func (tb *TLVBuilder) End() *TLVBuilder {
+	if tb.err != nil {
+		return tb
+	}
+	if len(tb.tags) == 0 {
+		tb.err = errors.New("byteman: TLV End without Begin")
+		return tb
+	}
+	value, tag := tb.frames[len(tb.frames)-1], tb.tags[len(tb.tags)-1]
+	tb.frames, tb.tags = tb.frames[:len(tb.frames)-1], tb.tags[:len(tb.tags)-1]
+	return tb.Add(tag, value)
+}
+
+// Bytes returns the encoded TLVs. It returns an error if a tag or length can not be encoded
+// or a constructed TLV is not ended.
+func (tb *TLVBuilder) Bytes() ([]byte, error) {
+	if tb.err != nil {
+		return nil, tb.err
+	}
+	if len(tb.tags) > 0 {
+		return nil, fmt.Errorf("byteman: TLV tag %#x is not ended", tb.tags[len(tb.tags)-1])
+	}
+	return tb.frames[0], nil
+}
+
+// appendTLV appends the encoded TLV by the given tag, value and TLV format.
+func appendTLV(b []byte, tag uint64, value []byte, f TLVFormat) ([]byte, error) {
+	bo := tlvByteOrder(f)
+	switch f.Tag {
+	case TLVTagUint8:
+		if tag > 0xff {
+			return nil, fmt.Errorf("byteman: TLV tag %#x overflows 1 byte", tag)
+		}
+		b = append(b, byte(tag))
+	case TLVTagUint16:
+		if tag > 0xffff {
+			return nil, fmt.Errorf("byteman: TLV tag %#x overflows 2 bytes", tag)
+		}
+		b = append(b, FromUint(uint16(tag), bo)...)
+	case TLVTagBER:
+		n := 1
+		for tag>>(8*uint(n)) != 0 && n < 8 {
+			n++
+		}
+		b = append(b, FromUint(tag, &BigEndian{})[8-n:]...)
+	default:
+		return nil, fmt.Errorf("byteman: invalid TLV tag type %d", f.Tag)
+	}
+
+	length := uint64(len(value))
+	switch f.Length {
+	case TLVLengthUint8:
+		if length > 0xff {
+			return nil, fmt.Errorf("byteman: TLV length %d overflows 1 byte", length)
+		}
+		b = append(b, byte(length))
+	case TLVLengthUint16:
+		if length > 0xffff {
+			return nil, fmt.Errorf("byteman: TLV length %d overflows 2 bytes", length)
+		}
+		b = append(b, FromUint(uint16(length), bo)...)
+	case TLVLengthUint32:
+		if length > 0xffffffff {
+			return nil, fmt.Errorf("byteman: TLV length %d overflows 4 bytes", length)
+		}
+		b = append(b, FromUint(uint32(length), bo)...)
+	case TLVLengthBER:
+		b = appendBERLength(b, length)
+	case TLVLengthVarint:
+		var buf [binary.MaxVarintLen64]byte
+		b = append(b, buf[:binary.PutUvarint(buf[:], length)]...)
+	default:
+		return nil, fmt.Errorf("byteman: invalid TLV length type %d", f.Length)
+	}
+	return append(b, value...), nil
+}
+
+// appendBERLength appends the BER (X.690) definite length in the shortest form.
+func appendBERLength(b []byte, length uint64) []byte {
+	if length < 0x80 {
+		return append(b, byte(length))
+	}
+	n := 1
+	for length>>(8*uint(n)) != 0 {
+		n++
+	}
+	b = append(b, 0x80|byte(n))
+	return append(b, FromUint(length, &BigEndian{})[8-n:]...)
+}
+
+// readTLVTag returns the tag and its size by the given byte slice and TLV format.
+func readTLVTag(b []byte, f TLVFormat) (uint64, int, error) {
+	switch f.Tag {
+	case TLVTagUint8:
+		if len(b) < 1 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return uint64(b[0]), 1, nil
+	case TLVTagUint16:
+		if len(b) < 2 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return uint64(Uint16(b[:2], tlvByteOrder(f))), 2, nil
+	case TLVTagBER:
+		if len(b) < 1 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		tag := uint64(b[0])
+		if b[0]&0x1f != 0x1f {
+			return tag, 1, nil
+		}
+		for i := 1; ; i++ {
+			if i >= len(b) {
+				return 0, 0, io.ErrUnexpectedEOF
+			}
+			if i >= 8 {
+				return 0, 0, errors.New("byteman: BER tag overflows 8 bytes")
+			}
+			tag = tag<<8 | uint64(b[i])
+			if b[i]&0x80 == 0 {
+				return tag, i + 1, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("byteman: invalid TLV tag type %d", f.Tag)
+}
+
+// readTLVLength returns the length and its size by the given byte slice and TLV format.
+func readTLVLength(b []byte, f TLVFormat) (uint64, int, error) {
+	bo := tlvByteOrder(f)
+	switch f.Length {
+	case TLVLengthUint8:
+		if len(b) < 1 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return uint64(b[0]), 1, nil
+	case TLVLengthUint16:
+		if len(b) < 2 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return uint64(Uint16(b[:2], bo)), 2, nil
+	case TLVLengthUint32:
+		if len(b) < 4 {
+			return 0, 0, io.ErrUnexpectedEOF
+		}
+		return uint64(Uint32(b[:4], bo)), 4, nil
+	case TLVLengthBER:
+		return readBERLength(b)
+	case TLVLengthVarint:
+		length, n := binary.Uvarint(b)
+		if n == 0 {
+			return 0, 0, io.ErrUnexpectedEOF
+		} else if n < 0 {
+			return 0, 0, errors.New("byteman: varint length overflows 64 bits")
+		}
+		return length, n, nil
+	}
+	return 0, 0, fmt.Errorf("byteman: invalid TLV length type %d", f.Length)
+}
+
+// readBERLength returns the BER (X.690) definite length and its size by the given byte slice.
+func readBERLength(b []byte) (uint64, int, error) {
+	if len(b) < 1 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	if b[0] < 0x80 {
+		return uint64(b[0]), 1, nil
+	}
+	n := int(b[0] & 0x7f)
+	switch {
+	case n == 0:
+		return 0, 0, errors.New("byteman: BER indefinite length is not supported")
+	case n > 8:
+		return 0, 0, fmt.Errorf("byteman: BER length of %d bytes overflows 64 bits", n)
+	case len(b) < 1+n:
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	var length uint64
+	for _, v := range b[1 : 1+n] {
+		length = length<<8 | uint64(v)
+	}
+	return length, 1 + n, nil
+}
+
+// isConstructedTLV returns whether the given tag is constructed by the given TLV format.
+func isConstructedTLV(tag uint64, f TLVFormat) bool {
+	if f.Constructed != nil {
+		return f.Constructed(tag)
+	}
+	if f.Tag != TLVTagBER {
+		return false
+	}
+	first := tag
+	for first > 0xff {
+		first >>= 8
+	}
+	return first&0x20 != 0
+}
+
+// tlvByteOrder returns the byte order by the given TLV format.
+func tlvByteOrder(f TLVFormat) ByteOrder {
+	if f.ByteOrder == nil {
+		return &BigEndian{}
+	}
+	return f.ByteOrder
+}
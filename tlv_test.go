@@ -0,0 +1,153 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/devfacet/byteman"
+)
+
+// emvFCI represents an EMV PSE select response (FCI template).
+var emvFCI = []byte{
+	0x6f, 0x1a, 0x84, 0x0e, 0x31, 0x50, 0x41, 0x59, 0x2e, 0x53, 0x59, 0x53, 0x2e, 0x44, 0x44, 0x46, 0x30, 0x31,
+	0xa5, 0x08, 0x88, 0x01, 0x02, 0x5f, 0x2d, 0x02, 0x65, 0x6e,
+}
+
+func TestParseTLV(t *testing.T) {
+	ber := byteman.TLVFormat{Tag: byteman.TLVTagBER, Length: byteman.TLVLengthBER}
+	nodes, err := byteman.ParseTLV(emvFCI, ber)
+	if err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+	if len(nodes) != 1 || nodes[0].Tag != 0x6f || !nodes[0].Constructed || len(nodes[0].Children) != 2 {
+		t.Fatalf("got %+v, want a constructed 0x6f node with 2 children", nodes)
+	}
+
+	table := []struct {
+		arg0 uint64
+		out0 []byte
+		out1 int
+		out2 int
+	}{
+		{0x84, []byte("1PAY.SYS.DDF01"), 2, 4},
+		{0x88, []byte{0x02}, 20, 22},
+		{0x5f2d, []byte("en"), 23, 26},
+	}
+	for _, v := range table {
+		n := byteman.FindTLV(nodes, v.arg0)
+		if n == nil {
+			t.Errorf("got nil, want tag %#x", v.arg0)
+			continue
+		}
+		if !bytes.Equal(n.Value, v.out0) || n.Offset != v.out1 || n.ValueOffset != v.out2 {
+			t.Errorf("got %v %v %v, want %v %v %v", n.Value, n.Offset, n.ValueOffset, v.out0, v.out1, v.out2)
+		}
+	}
+	if n := byteman.FindTLV(nodes, 0x9f02); n != nil {
+		t.Errorf("got %v, want nil", n)
+	}
+
+	var tags []uint64
+	var depths []int
+	byteman.WalkTLV(nodes, func(n *byteman.TLV, depth int) bool {
+		tags, depths = append(tags, n.Tag), append(depths, depth)
+		return true
+	})
+	if want := []uint64{0x6f, 0x84, 0xa5, 0x88, 0x5f2d}; len(tags) != len(want) || tags[4] != want[4] || depths[4] != 2 {
+		t.Errorf("got %v %v, want %v", tags, depths, want)
+	}
+
+	errs := []struct {
+		arg0 []byte
+		arg1 byteman.TLVFormat
+	}{
+		{[]byte{0x5f}, ber},
+		{[]byte{0x84}, ber},
+		{[]byte{0x84, 0x82, 0x01}, ber},
+		{[]byte{0x84, 0x80}, ber},
+		{[]byte{0x01, 0x00}, byteman.TLVFormat{Tag: byteman.TLVTagUint16, Length: byteman.TLVLengthUint16}},
+	}
+	for _, v := range errs {
+		if _, err := byteman.ParseTLV(v.arg0, v.arg1); err == nil {
+			t.Errorf("got nil, want error for %v", v.arg0)
+		}
+	}
+	if _, err := byteman.ParseTLV([]byte{0x84, 0x05, 0x01}, ber); err == nil {
+		t.Error("got nil, want error")
+	} else if e, ok := err.(*byteman.LengthError); !ok || e.Declared != 5 || e.Available != 1 {
+		t.Errorf("got %v, want LengthError", err)
+	}
+	if _, err := byteman.ParseTLV([]byte{0x01, 0x00}, byteman.TLVFormat{Tag: byteman.TLVTagUint8, Length: byteman.TLVLengthUint16}); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func BenchmarkParseTLV(b *testing.B) {
+	f := byteman.TLVFormat{Tag: byteman.TLVTagBER, Length: byteman.TLVLengthBER}
+	for i := 0; i < b.N; i++ {
+		byteman.ParseTLV(emvFCI, f)
+	}
+}
+
+func TestTLVBuilder(t *testing.T) {
+	ber := byteman.TLVFormat{Tag: byteman.TLVTagBER, Length: byteman.TLVLengthBER}
+	b, err := byteman.NewTLVBuilder(ber).
+		Begin(0x6f).
+		Add(0x84, []byte("1PAY.SYS.DDF01")).
+		Begin(0xa5).
+		Add(0x88, []byte{0x02}).
+		Add(0x5f2d, []byte("en")).
+		End().
+		End().
+		Bytes()
+	if err != nil || !bytes.Equal(b, emvFCI) {
+		t.Errorf("got %#v, %v, want %#v", b, err, emvFCI)
+	}
+
+	// The BER long form length.
+	b, err = byteman.NewTLVBuilder(ber).Add(0x04, make([]byte, 300)).Bytes()
+	if err != nil || !bytes.Equal(b[:4], []byte{0x04, 0x82, 0x01, 0x2c}) || len(b) != 304 {
+		t.Errorf("got %#v, %v, want %#v", b[:4], err, []byte{0x04, 0x82, 0x01, 0x2c})
+	}
+
+	// Fixed width little-endian tags and lengths with a custom constructed tag.
+	le := byteman.TLVFormat{
+		Tag:         byteman.TLVTagUint16,
+		Length:      byteman.TLVLengthUint16,
+		ByteOrder:   &byteman.LittleEndian{},
+		Constructed: func(tag uint64) bool { return tag == 0x0100 },
+	}
+	b, err = byteman.NewTLVBuilder(le).Begin(0x0100).Add(0x0002, []byte{0xaa}).End().Bytes()
+	want := []byte{0x00, 0x01, 0x05, 0x00, 0x02, 0x00, 0x01, 0x00, 0xaa}
+	if err != nil || !bytes.Equal(b, want) {
+		t.Errorf("got %#v, %v, want %#v", b, err, want)
+	}
+	nodes, err := byteman.ParseTLV(b, le)
+	if err != nil || len(nodes) != 1 || len(nodes[0].Children) != 1 || nodes[0].Children[0].Tag != 2 {
+		t.Errorf("got %+v, %v, want a nested node", nodes, err)
+	}
+
+	// Varint lengths.
+	varint := byteman.TLVFormat{Tag: byteman.TLVTagUint8, Length: byteman.TLVLengthVarint}
+	b, err = byteman.NewTLVBuilder(varint).Add(0x01, make([]byte, 200)).Bytes()
+	if err != nil || !bytes.Equal(b[:3], []byte{0x01, 0xc8, 0x01}) {
+		t.Errorf("got %#v, %v, want %#v", b[:3], err, []byte{0x01, 0xc8, 0x01})
+	}
+	if nodes, err := byteman.ParseTLV(b, varint); err != nil || len(nodes[0].Value) != 200 {
+		t.Errorf("got %v, want nil", err)
+	}
+
+	if _, err := byteman.NewTLVBuilder(ber).Begin(0x6f).Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.NewTLVBuilder(ber).End().Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+	if _, err := byteman.NewTLVBuilder(varint).Add(0x100, nil).Bytes(); err == nil {
+		t.Error("got nil, want error")
+	}
+}
@@ -0,0 +1,303 @@
+// Byteman
+// For the full copyright and license information, please view the LICENSE.txt file.
+
+package byteman
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	xxh3SecretSize   = 192
+	xxh3SecretMin    = 136
+	xxh3StripeLen    = 64
+	xxh3StripesBlock = (xxh3SecretSize - xxh3StripeLen) / 8
+	xxh3BlockLen     = xxh3StripeLen * xxh3StripesBlock
+	xxh3BufferSize   = 256
+	xxh3MidSizeMax   = 240
+)
+
+// xxh3Secret represents the XXH3 default secret.
+var xxh3Secret = [xxh3SecretSize]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+// XXH3 returns the 64 bit XXH3 hash of the given byte slice by the given seed.
+func XXH3(b []byte, seed uint64) uint64 {
+	if len(b) <= xxh3MidSizeMax {
+		return xxh3Short(b, seed)
+	}
+	secret := xxh3SecretBySeed(seed)
+	acc := xxh3InitAcc()
+	n := (len(b) - 1) / xxh3BlockLen
+	for i := 0; i < n; i++ {
+		xxh3Accumulate(&acc, b[i*xxh3BlockLen:], secret[:], xxh3StripesBlock)
+		xxh3Scramble(&acc, secret[xxh3SecretSize-xxh3StripeLen:])
+	}
+	stripes := (len(b) - 1 - n*xxh3BlockLen) / xxh3StripeLen
+	xxh3Accumulate(&acc, b[n*xxh3BlockLen:], secret[:], stripes)
+	xxh3Accumulate512(&acc, b[len(b)-xxh3StripeLen:], secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return xxh3Merge(&acc, secret[:], uint64(len(b)))
+}
+
+// NewXXH3 returns a new streaming 64 bit XXH3 hash by the given seed.
+func NewXXH3(seed uint64) hash.Hash64 {
+	d := &xxh3Digest{}
+	d.reset(seed)
+	return d
+}
+
+// xxh3Digest represents a streaming 64 bit XXH3 hash.
+type xxh3Digest struct {
+	seed    uint64
+	secret  [xxh3SecretSize]byte
+	acc     [8]uint64
+	buf     [xxh3BufferSize]byte
+	nbuf    int
+	stripes int
+	n       uint64
+}
+
+// reset resets the hash by the given seed.
+func (d *xxh3Digest) reset(seed uint64) {
+	d.seed, d.secret, d.acc = seed, xxh3SecretBySeed(seed), xxh3InitAcc()
+	d.nbuf, d.stripes, d.n = 0, 0, 0
+}
+
+// Write updates the hash by the given byte slice.
+// At least one byte is always kept in the buffer so the last stripe can be processed by Sum64.
+func (d *xxh3Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.n += uint64(n)
+	if len(p) <= xxh3BufferSize-d.nbuf {
+		d.nbuf += copy(d.buf[d.nbuf:], p)
+		return n, nil
+	}
+	if d.nbuf > 0 {
+		c := copy(d.buf[d.nbuf:], p)
+		p = p[c:]
+		d.consume(d.buf[:], xxh3BufferSize/xxh3StripeLen)
+		d.nbuf = 0
+	}
+	if len(p) > xxh3BufferSize {
+		i := 0
+		for ; len(p)-i > xxh3BufferSize; i += xxh3BufferSize {
+			d.consume(p[i:], xxh3BufferSize/xxh3StripeLen)
+		}
+		// Keep the last consumed stripe for the final stripe of short tails.
+		copy(d.buf[xxh3BufferSize-xxh3StripeLen:], p[i-xxh3StripeLen:i])
+		p = p[i:]
+	}
+	d.nbuf = copy(d.buf[:], p)
+	return n, nil
+}
+
+// consume accumulates the given number of stripes and scrambles at block boundaries.
+func (d *xxh3Digest) consume(p []byte, stripes int) {
+	if left := xxh3StripesBlock - d.stripes; left <= stripes {
+		xxh3Accumulate(&d.acc, p, d.secret[d.stripes*8:], left)
+		xxh3Scramble(&d.acc, d.secret[xxh3SecretSize-xxh3StripeLen:])
+		xxh3Accumulate(&d.acc, p[left*xxh3StripeLen:], d.secret[:], stripes-left)
+		d.stripes = stripes - left
+	} else {
+		xxh3Accumulate(&d.acc, p, d.secret[d.stripes*8:], stripes)
+		d.stripes += stripes
+	}
+}
+
+// Sum appends the current hash in big-endian byte order to the given byte slice.
+func (d *xxh3Digest) Sum(in []byte) []byte {
+	return append(in, FromUint(d.Sum64(), &BigEndian{})...)
+}
+
+// Sum64 returns the current hash.
+func (d *xxh3Digest) Sum64() uint64 {
+	if d.n <= xxh3MidSizeMax {
+		return xxh3Short(d.buf[:d.nbuf], d.seed)
+	}
+	acc := d.acc
+	var last []byte
+	if d.nbuf >= xxh3StripeLen {
+		state := *d
+		state.acc = acc
+		state.consume(d.buf[:], (d.nbuf-1)/xxh3StripeLen)
+		acc = state.acc
+		last = d.buf[d.nbuf-xxh3StripeLen : d.nbuf]
+	} else {
+		var tmp [xxh3StripeLen]byte
+		c := copy(tmp[:], d.buf[xxh3BufferSize-(xxh3StripeLen-d.nbuf):])
+		copy(tmp[c:], d.buf[:d.nbuf])
+		last = tmp[:]
+	}
+	xxh3Accumulate512(&acc, last, d.secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return xxh3Merge(&acc, d.secret[:], d.n)
+}
+
+// Reset resets the hash.
+func (d *xxh3Digest) Reset() {
+	d.reset(d.seed)
+}
+
+// Size returns the number of bytes Sum returns.
+func (d *xxh3Digest) Size() int {
+	return 8
+}
+
+// BlockSize returns the hash's underlying block size.
+func (d *xxh3Digest) BlockSize() int {
+	return xxh3StripeLen
+}
+
+// xxh3Short returns the XXH3 hash of the given byte slice of at most 240 bytes.
+func xxh3Short(b []byte, seed uint64) uint64 {
+	s := xxh3Secret[:]
+	n := uint64(len(b))
+	switch {
+	case n == 0:
+		return xxh64Avalanche(seed ^ le64(s[56:]) ^ le64(s[64:]))
+	case n <= 3:
+		combined := uint32(b[0])<<16 | uint32(b[n>>1])<<24 | uint32(b[n-1]) | uint32(n)<<8
+		return xxh64Avalanche(uint64(combined) ^ (uint64(le32(s)^le32(s[4:])) + seed))
+	case n <= 8:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		input := uint64(le32(b[n-4:])) + uint64(le32(b))<<32
+		return xxh3Rrmxmx(input^((le64(s[8:])^le64(s[16:]))-seed), n)
+	case n <= 16:
+		lo := le64(b) ^ ((le64(s[24:]) ^ le64(s[32:])) + seed)
+		hi := le64(b[n-8:]) ^ ((le64(s[40:]) ^ le64(s[48:])) - seed)
+		return xxh3Avalanche(n + bits.ReverseBytes64(lo) + hi + xxh3MulFold(lo, hi))
+	case n <= 128:
+		acc := n * prime64x1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					acc += xxh3Mix16(b[48:], s[96:], seed)
+					acc += xxh3Mix16(b[n-64:], s[112:], seed)
+				}
+				acc += xxh3Mix16(b[32:], s[64:], seed)
+				acc += xxh3Mix16(b[n-48:], s[80:], seed)
+			}
+			acc += xxh3Mix16(b[16:], s[32:], seed)
+			acc += xxh3Mix16(b[n-32:], s[48:], seed)
+		}
+		acc += xxh3Mix16(b, s, seed)
+		acc += xxh3Mix16(b[n-16:], s[16:], seed)
+		return xxh3Avalanche(acc)
+	}
+	acc := n * prime64x1
+	for i := 0; i < 8; i++ {
+		acc += xxh3Mix16(b[16*i:], s[16*i:], seed)
+	}
+	acc = xxh3Avalanche(acc)
+	for i := 8; i < len(b)/16; i++ {
+		acc += xxh3Mix16(b[16*i:], s[16*(i-8)+3:], seed)
+	}
+	acc += xxh3Mix16(b[n-16:], s[xxh3SecretMin-17:], seed)
+	return xxh3Avalanche(acc)
+}
+
+// xxh3SecretBySeed returns the XXH3 secret derived from the default secret by the given seed.
+func xxh3SecretBySeed(seed uint64) [xxh3SecretSize]byte {
+	secret := xxh3Secret
+	if seed == 0 {
+		return secret
+	}
+	for i := 0; i < xxh3SecretSize; i += 16 {
+		binary.LittleEndian.PutUint64(secret[i:], le64(xxh3Secret[i:])+seed)
+		binary.LittleEndian.PutUint64(secret[i+8:], le64(xxh3Secret[i+8:])-seed)
+	}
+	return secret
+}
+
+// xxh3InitAcc returns the initial XXH3 accumulators.
+func xxh3InitAcc() [8]uint64 {
+	return [8]uint64{prime32x3, prime64x1, prime64x2, prime64x3, prime64x4, prime32x2, prime64x5, prime32x1}
+}
+
+// xxh3Accumulate accumulates the given number of stripes.
+func xxh3Accumulate(acc *[8]uint64, p, secret []byte, stripes int) {
+	for i := 0; i < stripes; i++ {
+		xxh3Accumulate512(acc, p[i*xxh3StripeLen:], secret[i*8:])
+	}
+}
+
+// xxh3Accumulate512 accumulates a single stripe.
+func xxh3Accumulate512(acc *[8]uint64, p, secret []byte) {
+	for i := 0; i < 8; i++ {
+		v := le64(p[8*i:])
+		k := v ^ le64(secret[8*i:])
+		acc[i^1] += v
+		acc[i] += uint64(uint32(k)) * (k >> 32)
+	}
+}
+
+// xxh3Scramble scrambles the accumulators.
+func xxh3Scramble(acc *[8]uint64, secret []byte) {
+	for i := 0; i < 8; i++ {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= le64(secret[8*i:])
+		acc[i] = a * prime32x1
+	}
+}
+
+// xxh3Merge returns the XXH3 hash by the given accumulators, secret and input length.
+func xxh3Merge(acc *[8]uint64, secret []byte, n uint64) uint64 {
+	h := n * prime64x1
+	for i := 0; i < 4; i++ {
+		h += xxh3MulFold(acc[2*i]^le64(secret[11+16*i:]), acc[2*i+1]^le64(secret[11+16*i+8:]))
+	}
+	return xxh3Avalanche(h)
+}
+
+// xxh3Mix16 returns the XXH3 mix of the given 16 bytes.
+func xxh3Mix16(p, secret []byte, seed uint64) uint64 {
+	return xxh3MulFold(le64(p)^(le64(secret)+seed), le64(p[8:])^(le64(secret[8:])-seed))
+}
+
+// xxh3MulFold returns the XOR of the high and low halves of the 128 bit product of the given values.
+func xxh3MulFold(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+// xxh3Avalanche returns the XXH3 final mix of the given value.
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= 0x165667919e3779f9
+	h ^= h >> 32
+	return h
+}
+
+// xxh3Rrmxmx returns the XXH3 final mix of the given value for 4 to 8 byte inputs.
+func xxh3Rrmxmx(h, n uint64) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= 0x9fb21c651e98df25
+	h ^= (h >> 35) + n
+	h *= 0x9fb21c651e98df25
+	h ^= h >> 28
+	return h
+}
+
+// le64 returns the little-endian uint64 of the given byte slice.
+func le64(b []byte) uint64 {
+	return binary.LittleEndian.Uint64(b)
+}
+
+// le32 returns the little-endian uint32 of the given byte slice.
+func le32(b []byte) uint32 {
+	return binary.LittleEndian.Uint32(b)
+}